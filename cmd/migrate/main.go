@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"backend/internal/migrate"
+)
+
+const usage = `usage: migrate <command> [args]
+
+Commands:
+  up            apply all pending migrations
+  down [n]      roll back the last n applied migrations (default 1)
+  status        list migrations and whether they are applied
+  goto <v>      migrate up or down to version v (0 reverts everything)
+
+Environment:
+  DATABASE_URL  PostgreSQL connection string (required)
+`
+
+func main() {
+	slogHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+	slog.SetDefault(slog.New(slogHandler))
+
+	timeout := flag.Duration("timeout", 5*time.Minute, "overall timeout, including waiting for the migration lock")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		slog.Error("missing required environment variable", slog.String("var", "DATABASE_URL"))
+		os.Exit(1)
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		slog.Error("open database failed", slog.Any("err", err))
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	m, err := migrate.New(db)
+	if err != nil {
+		slog.Error("load migrations failed", slog.Any("err", err))
+		os.Exit(1)
+	}
+
+	if err := run(ctx, m, flag.Arg(0), flag.Args()[1:]); err != nil {
+		slog.Error("migrate failed", slog.String("command", flag.Arg(0)), slog.Any("err", err))
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, m *migrate.Migrator, cmd string, args []string) error {
+	switch cmd {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid step count %q", args[0])
+			}
+			steps = n
+		}
+		return m.Down(ctx, steps)
+	case "goto":
+		if len(args) < 1 {
+			return fmt.Errorf("goto requires a version")
+		}
+		v, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		return m.Goto(ctx, v)
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -14,6 +16,7 @@ import (
 
 	"backend/internal"
 	"backend/internal/config"
+	"backend/internal/migrate"
 )
 
 // @title           Payway API
@@ -33,29 +36,50 @@ func main() {
 
 	cfg := config.FromEnv()
 
-	db, err := setupDB(cfg.DatabaseURL)
+	db, err := setupDB(cfg.DatabaseURL, cfg.AutoMigrate)
 	if err != nil {
 		slog.Error("failed to setup database after retries", slog.Any("err", err))
 		os.Exit(1)
 	}
 	defer db.Close()
 
-	h, err := internal.NewHTTPServer(cfg, db)
+	h, stopWorkers, err := internal.NewHTTPServer(cfg, db)
 	if err != nil {
 		slog.Error("failed to start HTTP server", slog.Any("err", err))
 		os.Exit(1)
 	}
+	defer stopWorkers()
 
-	addr := ":8080"
-	slog.Info("listening", slog.String("addr", addr))
-	if err := http.ListenAndServe(addr, h); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Addr: ":8080", Handler: h}
+	errc := make(chan error, 1)
+	go func() {
+		slog.Info("listening", slog.String("addr", srv.Addr))
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
 		slog.Error("http server error", slog.Any("err", err))
+		stopWorkers()
 		os.Exit(1)
+	case <-ctx.Done():
+	}
+
+	// Let in-flight requests finish, then stop the background workers before the pool closes.
+	slog.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("http server shutdown failed", slog.Any("err", err))
 	}
 }
 
 // setupDB initializes the PostgreSQL pool and retries Ping using exponential backoff.
-func setupDB(dsn string) (*sql.DB, error) {
+// When autoMigrate is set, pending embedded migrations are applied before returning.
+func setupDB(dsn string, autoMigrate bool) (*sql.DB, error) {
 	// Open does not establish connections immediately.
 	db, err := sql.Open("pgx", dsn)
 	if err != nil {
@@ -91,5 +115,27 @@ func setupDB(dsn string) (*sql.DB, error) {
 	}
 
 	slog.Info("database connection established")
+
+	if autoMigrate {
+		if err := runMigrations(db); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
 	return db, nil
 }
+
+// runMigrations applies pending migrations. Replicas starting together wait on the migration lock.
+func runMigrations(db *sql.DB) error {
+	m, err := migrate.New(db)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if err := m.Up(ctx); err != nil {
+		return fmt.Errorf("auto-migrate: %w", err)
+	}
+	slog.Info("database schema up to date", slog.Int64("version", m.Latest()))
+	return nil
+}
@@ -2,10 +2,10 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
-
-	firebaseauth "firebase.google.com/go/v4/auth"
+	"time"
 
 	httpx "backend/internal/httpx"
 )
@@ -20,25 +20,64 @@ const (
 // User holds a subset of Firebase token/user info for downstream handlers.
 // Extend as needed.
 type User struct {
-	UID         string
-	Email       string
-	DisplayName string
-	Claims      map[string]any
+	UID           string
+	Email         string
+	EmailVerified bool // the provider confirmed the user controls Email
+	DisplayName   string
+	Claims        map[string]any
+	ExpiresAt     time.Time // when the ID token expires; zero for API keys
+
+	// APIKey is set when the request authenticated with a business API key. The principal then
+	// acts as the member who created the key, restricted to the key's business and scopes.
+	APIKey *APIKey
+}
+
+// IsPlatformAdmin reports whether u operates the platform itself rather than a business, which
+// is marked by the custom claim admin: true. API key principals never are.
+func IsPlatformAdmin(u *User) bool {
+	return u.APIKey == nil && u.Claims["admin"] == true
+}
+
+// RequirePlatformAdmin is a middleware that lets only platform admins through. It must run after
+// NewFirebaseMiddleware.
+func RequirePlatformAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, ok := FirebaseUser(w, r)
+		if !ok {
+			return
+		}
+		if !IsPlatformAdmin(u) {
+			httpx.WriteForbidden(w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
 // FirebaseUser extracts the authenticated Firebase user from the context.
 func FirebaseUser(w http.ResponseWriter, r *http.Request) (*User, bool) {
-	u, ok := r.Context().Value(ctxUserKey).(*User)
-	if !ok || u == nil || u.UID == "" {
+	u, ok := UserFromContext(r.Context())
+	if !ok {
 		httpx.WriteUnauthorized(w)
 		return nil, false
 	}
 	return u, true
 }
 
+// UserFromContext returns the authenticated user without writing a response.
+// It is meant for middleware that runs after NewFirebaseMiddleware.
+func UserFromContext(ctx context.Context) (*User, bool) {
+	u, ok := ctx.Value(ctxUserKey).(*User)
+	if !ok || u == nil || u.UID == "" {
+		return nil, false
+	}
+	return u, true
+}
+
 // NewFirebaseMiddleware returns an HTTP middleware that verifies Firebase ID tokens
-// from the Authorization: Bearer header. On success, it injects a User into context.
-func NewFirebaseMiddleware(authClient *firebaseauth.Client) func(http.Handler) http.Handler {
+// from the Authorization: Bearer header with v. On success, it injects a User into context.
+// When rc is not nil, the requests selected by its mode are also checked for revocation.
+func NewFirebaseMiddleware(v TokenVerifier, rc *RevocationCheck) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authz := r.Header.Get("Authorization")
@@ -48,22 +87,22 @@ func NewFirebaseMiddleware(authClient *firebaseauth.Client) func(http.Handler) h
 			}
 			raw := strings.TrimPrefix(authz, "Bearer ")
 
-			token, err := authClient.VerifyIDToken(r.Context(), raw)
-			if err != nil {
+			var u *User
+			var err error
+			if rc != nil && rc.applies(r.Method) {
+				u, err = rc.verify(r.Context(), raw)
+			} else {
+				u, err = v.VerifyIDToken(r.Context(), raw)
+			}
+			switch {
+			case errors.Is(err, ErrTokenRevoked), errors.Is(err, ErrUserDisabled):
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			case err != nil || u.UID == "":
 				http.Error(w, "invalid token", http.StatusUnauthorized)
 				return
 			}
 
-			// Optional: fetch user record for richer info
-			var email, displayName string
-			if token.UID != "" {
-				if ur, err := authClient.GetUser(r.Context(), token.UID); err == nil && ur != nil {
-					email = ur.Email
-					displayName = ur.DisplayName
-				}
-			}
-
-			u := &User{UID: token.UID, Email: email, DisplayName: displayName, Claims: token.Claims}
 			ctx := context.WithValue(r.Context(), ctxUserKey, u)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
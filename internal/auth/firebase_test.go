@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequirePlatformAdmin(t *testing.T) {
+	h := RequirePlatformAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		user   *User
+		status int
+	}{
+		{"anonymous", nil, http.StatusUnauthorized},
+		{"member", &User{UID: "u1"}, http.StatusForbidden},
+		{"admin claim not true", &User{UID: "u1", Claims: map[string]any{"admin": "true"}}, http.StatusForbidden},
+		{"api key with admin claim", &User{UID: "u1", Claims: map[string]any{"admin": true}, APIKey: &APIKey{}}, http.StatusForbidden},
+		{"admin", &User{UID: "u1", Claims: map[string]any{"admin": true}}, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+			if tt.user != nil {
+				req = req.WithContext(context.WithValue(req.Context(), ctxUserKey, tt.user))
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testKeyID    = "test-key"
+	testIssuer   = "https://issuer.test"
+	testAudience = "payway-test"
+)
+
+// testSigner mints tokens for the key set written by newTestVerifier.
+type testSigner struct {
+	key *rsa.PrivateKey
+	kid string
+}
+
+func newTestSigner(t *testing.T) testSigner {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testSigner{key: key, kid: testKeyID}
+}
+
+// newTestVerifier writes the public keys of signers to a key set file and loads it.
+func newTestVerifier(t *testing.T, signers ...testSigner) *JWKSVerifier {
+	t.Helper()
+	type jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	for _, s := range signers {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: s.kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		})
+	}
+	raw, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewJWKSVerifier(context.Background(), path, testIssuer, testAudience)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// validClaims returns claims the verifier of newTestVerifier accepts.
+func validClaims() map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"sub":            "user-1",
+		"email":          "ada@example.com",
+		"email_verified": true,
+		"name":           "Ada",
+		"iss":            testIssuer,
+		"aud":            testAudience,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+}
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// mint returns an RS256 token with claims signed by s.
+func (s testSigner) mint(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	signing := encodeSegment(t, map[string]string{"alg": "RS256", "typ": "JWT", "kid": s.kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWKSVerifierAcceptsValidToken(t *testing.T) {
+	s := newTestSigner(t)
+	v := newTestVerifier(t, s)
+
+	u, err := v.VerifyIDToken(context.Background(), s.mint(t, validClaims()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.UID != "user-1" || u.Email != "ada@example.com" || !u.EmailVerified || u.DisplayName != "Ada" {
+		t.Fatalf("user = %+v", u)
+	}
+}
+
+func TestJWKSVerifierRejectsBadSignature(t *testing.T) {
+	s := newTestSigner(t)
+	v := newTestVerifier(t, s)
+
+	// Same key ID, different key.
+	other := newTestSigner(t)
+	if _, err := v.VerifyIDToken(context.Background(), other.mint(t, validClaims())); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("token signed by another key: got %v, want %v", err, ErrInvalidToken)
+	}
+
+	// Claims changed after signing.
+	parts := strings.Split(s.mint(t, validClaims()), ".")
+	claims := validClaims()
+	claims["sub"] = "user-2"
+	parts[1] = encodeSegment(t, claims)
+	if _, err := v.VerifyIDToken(context.Background(), strings.Join(parts, ".")); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("tampered token: got %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestJWKSVerifierRejectsOtherAlgorithms(t *testing.T) {
+	s := newTestSigner(t)
+	v := newTestVerifier(t, s)
+	payload := encodeSegment(t, validClaims())
+
+	// alg none with an empty signature.
+	none := encodeSegment(t, map[string]string{"alg": "none", "kid": s.kid}) + "." + payload + "."
+	if _, err := v.VerifyIDToken(context.Background(), none); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("alg none: got %v, want %v", err, ErrInvalidToken)
+	}
+
+	// HS256 keyed with the public modulus, the classic key confusion attack.
+	signing := encodeSegment(t, map[string]string{"alg": "HS256", "kid": s.kid}) + "." + payload
+	mac := hmac.New(sha256.New, s.key.N.Bytes())
+	mac.Write([]byte(signing))
+	hs := signing + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	if _, err := v.VerifyIDToken(context.Background(), hs); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("alg HS256: got %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestJWKSVerifierRejectsInvalidClaims(t *testing.T) {
+	s := newTestSigner(t)
+	v := newTestVerifier(t, s)
+
+	tests := []struct {
+		name   string
+		modify func(map[string]any)
+	}{
+		{"expired", func(c map[string]any) { c["exp"] = time.Now().Add(-2 * clockSkew).Unix() }},
+		{"missing exp", func(c map[string]any) { delete(c, "exp") }},
+		{"not yet valid", func(c map[string]any) { c["nbf"] = time.Now().Add(2 * clockSkew).Unix() }},
+		{"issued in the future", func(c map[string]any) { c["iat"] = time.Now().Add(2 * clockSkew).Unix() }},
+		{"missing sub", func(c map[string]any) { delete(c, "sub") }},
+		{"wrong issuer", func(c map[string]any) { c["iss"] = "https://other.test" }},
+		{"missing issuer", func(c map[string]any) { delete(c, "iss") }},
+		{"wrong audience", func(c map[string]any) { c["aud"] = "other" }},
+		{"audience list without ours", func(c map[string]any) { c["aud"] = []string{"other", "another"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.modify(claims)
+			if _, err := v.VerifyIDToken(context.Background(), s.mint(t, claims)); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("got %v, want %v", err, ErrInvalidToken)
+			}
+		})
+	}
+
+	claims := validClaims()
+	claims["aud"] = []string{"other", testAudience}
+	if _, err := v.VerifyIDToken(context.Background(), s.mint(t, claims)); err != nil {
+		t.Fatalf("audience list with ours: %v", err)
+	}
+}
+
+func TestJWKSVerifierRejectsUnknownKey(t *testing.T) {
+	s := newTestSigner(t)
+	v := newTestVerifier(t, s)
+
+	other := newTestSigner(t)
+	other.kid = "other-key"
+	if _, err := v.VerifyIDToken(context.Background(), other.mint(t, validClaims())); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("got %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestFirebaseMiddlewareWithJWKSVerifier(t *testing.T) {
+	s := newTestSigner(t)
+	mw := NewFirebaseMiddleware(newTestVerifier(t, s), nil)
+
+	var got *User
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, ok := FirebaseUser(w, r)
+		if !ok {
+			return
+		}
+		got = u
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-2 * clockSkew).Unix()
+	tests := []struct {
+		name   string
+		authz  string
+		status int
+	}{
+		{"valid token", "Bearer " + s.mint(t, validClaims()), http.StatusNoContent},
+		{"no header", "", http.StatusUnauthorized},
+		{"not bearer", "Basic dXNlcjpwYXNz", http.StatusUnauthorized},
+		{"garbage", "Bearer not-a-token", http.StatusUnauthorized},
+		{"expired token", "Bearer " + s.mint(t, expired), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authz != "" {
+				req.Header.Set("Authorization", tt.authz)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status != http.StatusNoContent {
+				if got != nil {
+					t.Fatalf("handler ran with user %+v", got)
+				}
+				return
+			}
+			if got == nil || got.UID != "user-1" || got.Email != "ada@example.com" {
+				t.Fatalf("user in context = %+v", got)
+			}
+		})
+	}
+}
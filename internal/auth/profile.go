@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"container/list"
+	"context"
+	"expvar"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Profile is the account information not every ID token carries.
+type Profile struct {
+	Email         string
+	EmailVerified bool
+	DisplayName   string
+}
+
+// ProfileLookup fetches a user's profile from the identity provider.
+type ProfileLookup interface {
+	LookupProfile(ctx context.Context, uid string) (Profile, error)
+}
+
+// profileMetrics is published at /debug/vars, which only platform admins can read, as
+// auth_profile_cache.
+var profileMetrics = expvar.NewMap("auth_profile_cache")
+
+// ProfileCache is a bounded LRU cache in front of a ProfileLookup. Entries expire after ttl.
+// Hits, misses, evictions and lookup errors are counted in profileMetrics.
+type ProfileCache struct {
+	lookup ProfileLookup
+	size   int
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // front is the most recently used entry
+	entries map[string]*list.Element
+}
+
+type profileEntry struct {
+	uid       string
+	profile   Profile
+	expiresAt time.Time
+}
+
+// NewProfileCache caches up to size profiles from lookup for ttl each.
+func NewProfileCache(lookup ProfileLookup, size int, ttl time.Duration) *ProfileCache {
+	return &ProfileCache{
+		lookup:  lookup,
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+// LookupProfile returns the cached profile or fetches and caches it. Failed lookups are not cached.
+func (c *ProfileCache) LookupProfile(ctx context.Context, uid string) (Profile, error) {
+	now := time.Now()
+	c.mu.Lock()
+	if el, ok := c.entries[uid]; ok {
+		e := el.Value.(*profileEntry)
+		if now.Before(e.expiresAt) {
+			c.order.MoveToFront(el)
+			c.mu.Unlock()
+			profileMetrics.Add("hits", 1)
+			return e.profile, nil
+		}
+		c.order.Remove(el)
+		delete(c.entries, uid)
+	}
+	c.mu.Unlock()
+	profileMetrics.Add("misses", 1)
+
+	p, err := c.lookup.LookupProfile(ctx, uid)
+	if err != nil {
+		profileMetrics.Add("errors", 1)
+		return Profile{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[uid]; ok {
+		// A concurrent miss stored it first; refresh that entry.
+		c.order.Remove(el)
+		delete(c.entries, uid)
+	}
+	c.entries[uid] = c.order.PushFront(&profileEntry{uid: uid, profile: p, expiresAt: now.Add(c.ttl)})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*profileEntry).uid)
+		profileMetrics.Add("evictions", 1)
+	}
+	return p, nil
+}
+
+// WithProfile returns a middleware that completes the authenticated user's email and display name
+// through lookup when the token did not carry them. It runs after the auth middleware and only on
+// routes that need the profile; API key principals and a nil lookup pass through unchanged.
+// A failed lookup is logged and the request continues with what the token provided.
+func WithProfile(lookup ProfileLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if lookup == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u, ok := UserFromContext(r.Context())
+			if !ok || u.APIKey != nil || (u.Email != "" && u.DisplayName != "") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			p, err := lookup.LookupProfile(r.Context(), u.UID)
+			if err != nil {
+				slog.Warn("profile lookup failed", slog.String("component", "auth"), slog.String("firebase_id", u.UID), slog.Any("err", err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			completed := *u
+			if completed.Email == "" {
+				completed.Email = p.Email
+				completed.EmailVerified = p.EmailVerified
+			}
+			if completed.DisplayName == "" {
+				completed.DisplayName = p.DisplayName
+			}
+			ctx := context.WithValue(r.Context(), ctxUserKey, &completed)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"expvar"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrTokenRevoked is returned for tokens issued before the user's sessions were revoked.
+	ErrTokenRevoked = errors.New("token revoked")
+	// ErrUserDisabled is returned for tokens of disabled accounts.
+	ErrUserDisabled = errors.New("account disabled")
+)
+
+// RevocationMode selects which requests check that their token was not revoked.
+type RevocationMode string
+
+const (
+	RevocationOff    RevocationMode = "off"    // trust tokens until they expire
+	RevocationWrites RevocationMode = "writes" // check requests other than GET, HEAD and OPTIONS
+	RevocationAlways RevocationMode = "always" // check every request
+)
+
+// ParseRevocationMode validates a mode name.
+func ParseRevocationMode(s string) (RevocationMode, bool) {
+	switch m := RevocationMode(s); m {
+	case RevocationOff, RevocationWrites, RevocationAlways:
+		return m, true
+	}
+	return "", false
+}
+
+// SessionRevoker revokes every refresh token of a user, signing them out everywhere once their
+// current ID tokens are rejected.
+type SessionRevoker interface {
+	RevokeSessions(ctx context.Context, uid string) error
+}
+
+// RevocationVerifier is implemented by verifiers that can also reject revoked tokens and
+// disabled accounts, reporting them as ErrTokenRevoked and ErrUserDisabled.
+type RevocationVerifier interface {
+	TokenVerifier
+	SessionRevoker
+	VerifyIDTokenAndCheckRevoked(ctx context.Context, raw string) (*User, error)
+}
+
+// revocationMetrics is published at /debug/vars as auth_revocation_check.
+var revocationMetrics = expvar.NewMap("auth_revocation_check")
+
+// maxRevocationEntries bounds the cache of recently checked tokens.
+const maxRevocationEntries = 10000
+
+// RevocationCheck runs the provider's revocation check on the requests selected by its mode.
+// A token that passed is trusted for ttl, or until it expires if that is sooner, so a revocation
+// takes up to ttl to apply on other replicas; sessions revoked through RevokeSessions apply
+// immediately on this one.
+type RevocationCheck struct {
+	verifier RevocationVerifier
+	mode     RevocationMode
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	checked map[[sha256.Size]byte]revocationEntry
+}
+
+type revocationEntry struct {
+	user      *User
+	expiresAt time.Time
+}
+
+// NewRevocationCheck checks tokens with v according to mode and caches passing tokens for at
+// most ttl.
+func NewRevocationCheck(v RevocationVerifier, mode RevocationMode, ttl time.Duration) *RevocationCheck {
+	return &RevocationCheck{
+		verifier: v,
+		mode:     mode,
+		ttl:      ttl,
+		checked:  make(map[[sha256.Size]byte]revocationEntry),
+	}
+}
+
+// applies reports whether requests with the given method are checked.
+func (c *RevocationCheck) applies(method string) bool {
+	switch c.mode {
+	case RevocationAlways:
+		return true
+	case RevocationWrites:
+		return method != http.MethodGet && method != http.MethodHead && method != http.MethodOptions
+	default:
+		return false
+	}
+}
+
+// verify verifies raw and checks it was not revoked, reusing a recent passing check.
+func (c *RevocationCheck) verify(ctx context.Context, raw string) (*User, error) {
+	key := sha256.Sum256([]byte(raw))
+	now := time.Now()
+
+	c.mu.Lock()
+	if e, ok := c.checked[key]; ok && now.Before(e.expiresAt) {
+		c.mu.Unlock()
+		revocationMetrics.Add("hits", 1)
+		return e.user, nil
+	}
+	c.mu.Unlock()
+	revocationMetrics.Add("misses", 1)
+
+	u, err := c.verifier.VerifyIDTokenAndCheckRevoked(ctx, raw)
+	if err != nil {
+		if errors.Is(err, ErrTokenRevoked) || errors.Is(err, ErrUserDisabled) {
+			revocationMetrics.Add("rejected", 1)
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.checked) >= maxRevocationEntries {
+		for k, e := range c.checked {
+			if !now.Before(e.expiresAt) {
+				delete(c.checked, k)
+			}
+		}
+		if len(c.checked) >= maxRevocationEntries {
+			c.checked = make(map[[sha256.Size]byte]revocationEntry)
+		}
+	}
+	deadline := now.Add(c.ttl)
+	if !u.ExpiresAt.IsZero() && u.ExpiresAt.Before(deadline) {
+		deadline = u.ExpiresAt
+	}
+	c.checked[key] = revocationEntry{user: u, expiresAt: deadline}
+	return u, nil
+}
+
+// RevokeSessions revokes the user's sessions with the provider and forgets their cached checks.
+func (c *RevocationCheck) RevokeSessions(ctx context.Context, uid string) error {
+	if err := c.verifier.RevokeSessions(ctx, uid); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.checked {
+		if e.user.UID == uid {
+			delete(c.checked, k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeRevoker passes every token, reporting it as expiring at exp, and counts the checks.
+type fakeRevoker struct {
+	exp    time.Time
+	checks int
+}
+
+func (f *fakeRevoker) VerifyIDToken(ctx context.Context, raw string) (*User, error) {
+	return f.VerifyIDTokenAndCheckRevoked(ctx, raw)
+}
+
+func (f *fakeRevoker) VerifyIDTokenAndCheckRevoked(_ context.Context, raw string) (*User, error) {
+	f.checks++
+	return &User{UID: raw, ExpiresAt: f.exp}, nil
+}
+
+func (f *fakeRevoker) RevokeSessions(context.Context, string) error { return nil }
+
+func TestRevocationCacheDeadline(t *testing.T) {
+	const ttl = time.Hour
+	now := time.Now()
+	tests := []struct {
+		name string
+		exp  time.Time
+		want time.Time // cache deadline, compared with a second of slack
+	}{
+		{"token outlives the ttl", now.Add(2 * time.Hour), now.Add(ttl)},
+		{"token expires within the ttl", now.Add(10 * time.Minute), now.Add(10 * time.Minute)},
+		{"no expiry known", time.Time{}, now.Add(ttl)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewRevocationCheck(&fakeRevoker{exp: tt.exp}, RevocationAlways, ttl)
+			if _, err := c.verify(context.Background(), "token"); err != nil {
+				t.Fatal(err)
+			}
+			var got time.Time
+			for _, e := range c.checked {
+				got = e.expiresAt
+			}
+			if d := got.Sub(tt.want); d < -time.Second || d > time.Second {
+				t.Fatalf("cached until %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevocationCacheDropsExpiredTokens(t *testing.T) {
+	v := &fakeRevoker{exp: time.Now().Add(-time.Second)}
+	c := NewRevocationCheck(v, RevocationAlways, time.Hour)
+	for range 2 {
+		if _, err := c.verify(context.Background(), "token"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v.checks != 2 {
+		t.Fatalf("provider checked %d times, want 2: an expired token must not be served from the cache", v.checks)
+	}
+
+	v.exp = time.Now().Add(time.Hour)
+	for range 2 {
+		if _, err := c.verify(context.Background(), "fresh"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v.checks != 3 {
+		t.Fatalf("provider checked %d times, want 3", v.checks)
+	}
+}
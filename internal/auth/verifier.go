@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	firebaseauth "firebase.google.com/go/v4/auth"
+)
+
+// TokenVerifier checks a bearer ID token and returns the user it identifies.
+// Implementations must be safe for concurrent use.
+type TokenVerifier interface {
+	VerifyIDToken(ctx context.Context, raw string) (*User, error)
+}
+
+// AccountCreator creates the sign-in account behind self-registration and returns its UID.
+type AccountCreator interface {
+	CreateAccount(ctx context.Context, email, password string) (uid string, err error)
+}
+
+// Firebase verifies tokens and creates accounts with Firebase Auth. A client created for the
+// Auth emulator makes it work against the emulator instead.
+type Firebase struct {
+	Client *firebaseauth.Client
+}
+
+// VerifyIDToken verifies the token with Firebase. Email and display name come from the token's
+// claims; accounts without them can be completed per route with WithProfile.
+func (f Firebase) VerifyIDToken(ctx context.Context, raw string) (*User, error) {
+	token, err := f.Client.VerifyIDToken(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	email, _ := token.Claims["email"].(string)
+	verified, _ := token.Claims["email_verified"].(bool)
+	name, _ := token.Claims["name"].(string)
+	return &User{UID: token.UID, Email: email, EmailVerified: verified, DisplayName: name, Claims: token.Claims, ExpiresAt: time.Unix(token.Expires, 0)}, nil
+}
+
+// VerifyIDTokenAndCheckRevoked verifies the token and asks Firebase whether it was revoked or
+// the account disabled.
+func (f Firebase) VerifyIDTokenAndCheckRevoked(ctx context.Context, raw string) (*User, error) {
+	token, err := f.Client.VerifyIDTokenAndCheckRevoked(ctx, raw)
+	switch {
+	case firebaseauth.IsIDTokenRevoked(err):
+		return nil, ErrTokenRevoked
+	case firebaseauth.IsUserDisabled(err):
+		return nil, ErrUserDisabled
+	case err != nil:
+		return nil, err
+	}
+	email, _ := token.Claims["email"].(string)
+	verified, _ := token.Claims["email_verified"].(bool)
+	name, _ := token.Claims["name"].(string)
+	return &User{UID: token.UID, Email: email, EmailVerified: verified, DisplayName: name, Claims: token.Claims, ExpiresAt: time.Unix(token.Expires, 0)}, nil
+}
+
+// RevokeSessions revokes every refresh token of the Firebase user.
+func (f Firebase) RevokeSessions(ctx context.Context, uid string) error {
+	return f.Client.RevokeRefreshTokens(ctx, uid)
+}
+
+// LookupProfile fetches the account record from Firebase.
+func (f Firebase) LookupProfile(ctx context.Context, uid string) (Profile, error) {
+	ur, err := f.Client.GetUser(ctx, uid)
+	if err != nil {
+		return Profile{}, err
+	}
+	return Profile{Email: ur.Email, EmailVerified: ur.EmailVerified, DisplayName: ur.DisplayName}, nil
+}
+
+// CreateAccount creates an email/password user in Firebase.
+func (f Firebase) CreateAccount(ctx context.Context, email, password string) (string, error) {
+	params := (&firebaseauth.UserToCreate{}).
+		Email(email).
+		Password(password)
+	ur, err := f.Client.CreateUser(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("firebase: %w", err)
+	}
+	return ur.UID, nil
+}
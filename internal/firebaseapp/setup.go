@@ -2,7 +2,9 @@ package firebaseapp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 
 	firebase "firebase.google.com/go/v4"
 	firebaseauth "firebase.google.com/go/v4/auth"
@@ -36,3 +38,24 @@ func New(ctx context.Context, credentialsFile string) (*firebase.App, *firebasea
 
 	return app, authClient, nil
 }
+
+// NewEmulator initializes a Firebase App and Auth client talking to the Firebase Auth emulator
+// named by FIREBASE_AUTH_EMULATOR_HOST (e.g. "localhost:9099"), which the SDK reads itself. No
+// Google credentials are needed; the emulator accepts unsigned tokens for projectID.
+func NewEmulator(ctx context.Context, projectID string) (*firebase.App, *firebaseauth.Client, error) {
+	if os.Getenv("FIREBASE_AUTH_EMULATOR_HOST") == "" {
+		return nil, nil, errors.New("firebase: FIREBASE_AUTH_EMULATOR_HOST is not set")
+	}
+
+	app, err := firebase.NewApp(ctx, &firebase.Config{ProjectID: projectID}, option.WithoutAuthentication())
+	if err != nil {
+		return nil, nil, fmt.Errorf("firebase: failed to initialize emulator app: %w", err)
+	}
+
+	authClient, err := app.Auth(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("firebase: failed to init emulator auth client: %w", err)
+	}
+
+	return app, authClient, nil
+}
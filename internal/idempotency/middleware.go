@@ -0,0 +1,185 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+)
+
+const (
+	// HeaderKey is the request header clients set to make a mutating request safe to retry.
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is set on responses served from a stored result.
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	maxBodyBytes = 1 << 20
+
+	// staleAfter is how long an unfinished request may hold its key before a retry may take it over.
+	// It is well above the router's request timeout.
+	staleAfter = 2 * time.Minute
+)
+
+// Middleware honors the Idempotency-Key header on mutating requests. It must run after the
+// auth middleware: keys are scoped to the authenticated principal and requests without one pass through.
+//
+// The first request with a key is executed and its response stored together with a fingerprint of
+// method, path and body. Retries with the same key replay the stored response; a different request
+// under the same key gets 422, and a retry while the original is still running gets 409.
+// Server errors (5xx) are not stored, so the client may retry them. Keys expire after ttl.
+func Middleware(db *sql.DB, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderKey)
+			if key == "" || !isMutating(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			u, ok := auth.UserFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxKeyLength {
+				httpx.WriteErr(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+			_ = r.Body.Close()
+			if err != nil {
+				httpx.WriteBadRequest(w)
+				return
+			}
+			if len(body) > maxBodyBytes {
+				httpx.WriteErr(w, http.StatusRequestEntityTooLarge, "request body too large")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			s := store{db: db, principal: principalKey(u), key: key}
+			logger := slog.Default().With(
+				slog.String("component", "idempotency"),
+				slog.String("principal", s.principal),
+				slog.String("key", key),
+			)
+
+			fp := fingerprint(r.Method, r.URL.Path, body)
+			claimed, err := s.claim(r.Context(), r.Method, r.URL.Path, fp, ttl)
+			if err != nil {
+				logger.Error("claim idempotency key failed", slog.Any("err", err))
+				httpx.WriteInternalServerError(w)
+				return
+			}
+			if !claimed {
+				s.replay(w, r, fp, logger)
+				return
+			}
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			finished := false
+			defer func() {
+				// Use a fresh context: the request context may already be cancelled.
+				ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+				defer cancel()
+				// A panicking handler never finishes; release the key so the client can retry.
+				if !finished || rec.status >= http.StatusInternalServerError {
+					err = s.release(ctx)
+				} else {
+					err = s.complete(ctx, rec.status, rec.Header().Get("Content-Type"), rec.body.Bytes())
+				}
+				if err != nil {
+					logger.Error("store idempotent response failed", slog.Any("err", err))
+				}
+			}()
+			next.ServeHTTP(rec, r)
+			finished = true
+		})
+	}
+}
+
+// replay serves the stored outcome of a key that has already been claimed.
+func (s store) replay(w http.ResponseWriter, r *http.Request, fp []byte, logger *slog.Logger) {
+	rec, err := s.load(r.Context())
+	if errors.Is(err, sql.ErrNoRows) {
+		// The original request failed with a 5xx and released the key between claim and load.
+		httpx.WriteErr(w, http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+		return
+	} else if err != nil {
+		logger.Error("load idempotency key failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	if !bytes.Equal(rec.fingerprint, fp) {
+		httpx.WriteErr(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+		return
+	}
+	if rec.statusCode == nil {
+		httpx.WriteErr(w, http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+		return
+	}
+	if rec.contentType != "" {
+		w.Header().Set("Content-Type", rec.contentType)
+	}
+	w.Header().Set(HeaderReplayed, "true")
+	w.WriteHeader(*rec.statusCode)
+	_, _ = w.Write(rec.body)
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// principalKey scopes keys to the authenticated principal. API keys get their own namespace so
+// they never replay responses of their creator's interactive requests.
+func principalKey(u *auth.User) string {
+	if u.APIKey != nil {
+		return "api_key:" + u.APIKey.ID
+	}
+	return "user:" + u.UID
+}
+
+func fingerprint(method, path string, body []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// recorder passes the response through while keeping a copy for storage.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *recorder) WriteHeader(code int) {
+	if !rec.wroteHeader {
+		rec.status = code
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
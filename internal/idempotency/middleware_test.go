@@ -0,0 +1,230 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/auth"
+	"backend/internal/dbtest"
+)
+
+// stubVerifier accepts the tokens it was given, each standing for one user.
+type stubVerifier map[string]*auth.User
+
+func (v stubVerifier) VerifyIDToken(_ context.Context, raw string) (*auth.User, error) {
+	if u, ok := v[raw]; ok {
+		return u, nil
+	}
+	return nil, errors.New("unknown token")
+}
+
+var users = stubVerifier{
+	"alice": {UID: "alice"},
+	"bob":   {UID: "bob"},
+}
+
+// counting answers 201 with the number of requests it has executed so far.
+type counting struct{ calls atomic.Int32 }
+
+func (c *counting) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := c.calls.Add(1)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, `{"call":%d}`, n)
+}
+
+// serve mounts next behind authentication and the idempotency middleware.
+func serve(db *sql.DB, ttl time.Duration, next http.Handler) http.Handler {
+	return auth.NewFirebaseMiddleware(users, nil)(Middleware(db, ttl)(next))
+}
+
+func do(h http.Handler, token, key, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	if key != "" {
+		req.Header.Set(HeaderKey, key)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestReplay(t *testing.T) {
+	db := dbtest.Open(t)
+	next := &counting{}
+	h := serve(db, time.Hour, next)
+
+	first := do(h, "alice", "k1", http.MethodPost, "/orders", `{"amount":"1.00"}`)
+	if first.Code != http.StatusCreated || first.Header().Get(HeaderReplayed) != "" {
+		t.Fatalf("first request: status = %d, replayed = %q", first.Code, first.Header().Get(HeaderReplayed))
+	}
+	retry := do(h, "alice", "k1", http.MethodPost, "/orders", `{"amount":"1.00"}`)
+	if retry.Code != http.StatusCreated || retry.Header().Get(HeaderReplayed) != "true" {
+		t.Fatalf("retry: status = %d, replayed = %q", retry.Code, retry.Header().Get(HeaderReplayed))
+	}
+	if retry.Body.String() != first.Body.String() || retry.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("retry body = %s (%s), want %s", retry.Body, retry.Header().Get("Content-Type"), first.Body)
+	}
+	if n := next.calls.Load(); n != 1 {
+		t.Fatalf("handler ran %d times, want 1", n)
+	}
+
+	// Keys are scoped to the principal; requests without a key or that do not mutate pass through.
+	do(h, "bob", "k1", http.MethodPost, "/orders", `{"amount":"1.00"}`)
+	do(h, "alice", "", http.MethodPost, "/orders", `{"amount":"1.00"}`)
+	do(h, "alice", "k1", http.MethodGet, "/orders", "")
+	if n := next.calls.Load(); n != 4 {
+		t.Fatalf("handler ran %d times, want 4", n)
+	}
+}
+
+func TestFingerprintMismatch(t *testing.T) {
+	db := dbtest.Open(t)
+	next := &counting{}
+	h := serve(db, time.Hour, next)
+
+	if rec := do(h, "alice", "k1", http.MethodPost, "/orders", `{"amount":"1.00"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("first request: status = %d", rec.Code)
+	}
+	tests := []struct {
+		name, method, path, body string
+	}{
+		{"different body", http.MethodPost, "/orders", `{"amount":"2.00"}`},
+		{"different path", http.MethodPost, "/refunds", `{"amount":"1.00"}`},
+		{"different method", http.MethodPut, "/orders", `{"amount":"1.00"}`},
+	}
+	for _, tt := range tests {
+		if rec := do(h, "alice", "k1", tt.method, tt.path, tt.body); rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: status = %d, want 422", tt.name, rec.Code)
+		}
+	}
+	if n := next.calls.Load(); n != 1 {
+		t.Fatalf("handler ran %d times, want 1", n)
+	}
+}
+
+func TestInProgress(t *testing.T) {
+	db := dbtest.Open(t)
+	entered, release := make(chan struct{}), make(chan struct{})
+	h := serve(db, time.Hour, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	done := make(chan int)
+	go func() { done <- do(h, "alice", "k1", http.MethodPost, "/orders", `{}`).Code }()
+	<-entered
+
+	if rec := do(h, "alice", "k1", http.MethodPost, "/orders", `{}`); rec.Code != http.StatusConflict {
+		t.Errorf("retry while in progress: status = %d, want 409", rec.Code)
+	}
+	close(release)
+	if code := <-done; code != http.StatusCreated {
+		t.Fatalf("original request: status = %d, want 201", code)
+	}
+	if rec := do(h, "alice", "k1", http.MethodPost, "/orders", `{}`); rec.Code != http.StatusCreated || rec.Header().Get(HeaderReplayed) != "true" {
+		t.Fatalf("retry after completion: status = %d, replayed = %q", rec.Code, rec.Header().Get(HeaderReplayed))
+	}
+}
+
+func TestServerErrorsAreNotStored(t *testing.T) {
+	db := dbtest.Open(t)
+	var calls atomic.Int32
+	h := serve(db, time.Hour, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			panic("handler failed")
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+
+	if rec := do(h, "alice", "k1", http.MethodPost, "/orders", `{}`); rec.Code != http.StatusBadGateway {
+		t.Fatalf("first request: status = %d, want 502", rec.Code)
+	}
+	func() {
+		defer func() { _ = recover() }()
+		do(h, "alice", "k1", http.MethodPost, "/orders", `{}`)
+	}()
+	if rec := do(h, "alice", "k1", http.MethodPost, "/orders", `{}`); rec.Code != http.StatusCreated || rec.Header().Get(HeaderReplayed) != "" {
+		t.Fatalf("retry after failures: status = %d, replayed = %q", rec.Code, rec.Header().Get(HeaderReplayed))
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("handler ran %d times, want 3", n)
+	}
+
+	// Client errors are final and replayed like any other response.
+	h = serve(db, time.Hour, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	do(h, "alice", "k2", http.MethodPost, "/orders", `{}`)
+	if rec := do(h, "alice", "k2", http.MethodPost, "/orders", `{}`); rec.Code != http.StatusBadRequest || rec.Header().Get(HeaderReplayed) != "true" {
+		t.Fatalf("retry of a 400: status = %d, replayed = %q", rec.Code, rec.Header().Get(HeaderReplayed))
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	db := dbtest.Open(t)
+	next := &counting{}
+	h := serve(db, 50*time.Millisecond, next)
+
+	do(h, "alice", "k1", http.MethodPost, "/orders", `{}`)
+	time.Sleep(100 * time.Millisecond)
+
+	// An expired key is taken over by the next request with it.
+	if rec := do(h, "alice", "k1", http.MethodPost, "/orders", `{"other":true}`); rec.Code != http.StatusCreated || rec.Header().Get(HeaderReplayed) != "" {
+		t.Fatalf("request with an expired key: status = %d, replayed = %q", rec.Code, rec.Header().Get(HeaderReplayed))
+	}
+
+	// The janitor removes expired keys and keeps live ones.
+	do(h, "alice", "k2", http.MethodPost, "/orders", `{}`)
+	time.Sleep(100 * time.Millisecond)
+	live := serve(db, time.Hour, next)
+	do(live, "alice", "k3", http.MethodPost, "/orders", `{}`)
+
+	n, err := purgeExpired(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("purged %d keys, want 2", n)
+	}
+	var left []string
+	rows, err := db.Query(`SELECT key FROM idempotency_key ORDER BY key`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			t.Fatal(err)
+		}
+		left = append(left, k)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 1 || left[0] != "k3" {
+		t.Fatalf("keys left = %v, want [k3]", left)
+	}
+}
+
+func TestLongKeyRejected(t *testing.T) {
+	h := serve(nil, time.Hour, &counting{})
+	if rec := do(h, "alice", strings.Repeat("k", maxKeyLength+1), http.MethodPost, "/orders", `{}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
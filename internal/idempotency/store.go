@@ -0,0 +1,109 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+// store reads and writes a single idempotency_key row.
+type store struct {
+	db        *sql.DB
+	principal string
+	key       string
+}
+
+type storedResponse struct {
+	fingerprint []byte
+	statusCode  *int
+	contentType string
+	body        []byte
+}
+
+// claim inserts the key in the in-progress state. It returns false when the key is already taken.
+// Expired keys and keys abandoned by a crashed request are removed first so they can be reused.
+func (s store) claim(ctx context.Context, method, path string, fp []byte, ttl time.Duration) (bool, error) {
+	if _, err := s.db.ExecContext(ctx, `
+		DELETE FROM idempotency_key
+		WHERE principal = $1 AND key = $2
+		  AND (expires_at <= now() OR (status_code IS NULL AND created_at <= now() - make_interval(secs => $3)))`,
+		s.principal, s.key, staleAfter.Seconds(),
+	); err != nil {
+		return false, err
+	}
+
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO idempotency_key (principal, key, method, path, fingerprint, expires_at)
+		VALUES ($1, $2, $3, $4, $5, now() + make_interval(secs => $6))
+		ON CONFLICT (principal, key) DO NOTHING`,
+		s.principal, s.key, method, path, fp, ttl.Seconds(),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (s store) load(ctx context.Context) (storedResponse, error) {
+	var rec storedResponse
+	var contentType sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		SELECT fingerprint, status_code, content_type, response_body
+		FROM idempotency_key
+		WHERE principal = $1 AND key = $2`,
+		s.principal, s.key,
+	).Scan(&rec.fingerprint, &rec.statusCode, &contentType, &rec.body)
+	rec.contentType = contentType.String
+	return rec, err
+}
+
+// complete stores the response of a finished request for later replay.
+func (s store) complete(ctx context.Context, status int, contentType string, body []byte) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE idempotency_key
+		SET status_code = $3, content_type = $4, response_body = $5, completed_at = now()
+		WHERE principal = $1 AND key = $2`,
+		s.principal, s.key, status, contentType, body,
+	)
+	return err
+}
+
+// release forgets a key whose request failed, so the client may retry it.
+func (s store) release(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM idempotency_key WHERE principal = $1 AND key = $2`, s.principal, s.key,
+	)
+	return err
+}
+
+// RunJanitor deletes expired keys every interval until ctx is cancelled.
+func RunJanitor(ctx context.Context, db *sql.DB, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			n, err := purgeExpired(ctx, db)
+			if err != nil {
+				slog.Warn("purge expired idempotency keys failed", slog.Any("err", err))
+				continue
+			}
+			if n > 0 {
+				slog.Info("purged expired idempotency keys", slog.Int64("count", n))
+			}
+		}
+	}
+}
+
+// purgeExpired deletes expired keys and returns how many there were.
+func purgeExpired(ctx context.Context, db *sql.DB) (int64, error) {
+	res, err := db.ExecContext(ctx, `DELETE FROM idempotency_key WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileSink writes each message to Dir instead of sending it, for local development and tests.
+// Every message produces <name>.eml with the full encoded message and <name>.html with just the
+// rendered body, which opens directly in a browser.
+type FileSink struct {
+	Dir  string
+	From string
+}
+
+// Send writes m to the sink directory, creating it when needed.
+func (f *FileSink) Send(_ context.Context, m Message) error {
+	now := time.Now()
+	raw, err := m.Bytes(f.From, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+
+	id := m.ID
+	if id == "" {
+		b := make([]byte, 8)
+		_, _ = rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+	name := filepath.Join(f.Dir, now.UTC().Format("20060102T150405")+"-"+id)
+	if err := os.WriteFile(name+".eml", raw, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(name+".html", []byte(m.HTML), 0o644)
+}
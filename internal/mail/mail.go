@@ -0,0 +1,79 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"time"
+)
+
+// Message is a rendered email ready to be delivered.
+// ID is stable across retries and becomes the Message-ID, so receivers can drop duplicates.
+type Message struct {
+	ID      string
+	To      string
+	Subject string
+	HTML    string
+}
+
+// Mailer delivers messages. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// Config selects and configures a Mailer.
+type Config struct {
+	Driver string // "file" or "smtp"
+	From   string // sender address, e.g. "Payway <no-reply@payway.bz>"
+
+	FileDir string // where the file driver writes messages
+
+	SMTPAddr     string // host:port of the SMTP server
+	SMTPUsername string // optional; enables PLAIN authentication
+	SMTPPassword string
+}
+
+// New returns the mailer selected by cfg.Driver.
+func New(cfg Config) (Mailer, error) {
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("mail: invalid sender %q: %w", cfg.From, err)
+	}
+	switch cfg.Driver {
+	case "file":
+		return &FileSink{Dir: cfg.FileDir, From: cfg.From}, nil
+	case "smtp":
+		if cfg.SMTPAddr == "" {
+			return nil, fmt.Errorf("mail: smtp driver needs an address")
+		}
+		return &SMTP{Addr: cfg.SMTPAddr, Username: cfg.SMTPUsername, Password: cfg.SMTPPassword, From: cfg.From}, nil
+	default:
+		return nil, fmt.Errorf("mail: unknown driver %q", cfg.Driver)
+	}
+}
+
+// Bytes encodes m as a single-part HTML message sent from from.
+func (m Message) Bytes(from string, now time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	if m.ID != "" {
+		fmt.Fprintf(&b, "Message-ID: <%s@payway>\r\n", m.ID)
+	}
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/html; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&b)
+	if _, err := qp.Write([]byte(m.HTML)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "order";
+DROP TABLE IF EXISTS business_user;
+DROP TABLE IF EXISTS business;
+DROP TABLE IF EXISTS "user";
@@ -0,0 +1,43 @@
+-- Baseline schema. Tables use IF NOT EXISTS so that databases that were
+-- built by hand before migrations existed can adopt this version as-is.
+
+CREATE TABLE IF NOT EXISTS "user" (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    firebase_id text NOT NULL UNIQUE,
+    name        text,
+    last_name   text,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS business (
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS business_user (
+    business_id uuid NOT NULL REFERENCES business (id) ON DELETE CASCADE,
+    user_id     uuid NOT NULL REFERENCES "user" (id) ON DELETE CASCADE,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (business_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS business_user_user_id_idx ON business_user (user_id);
+
+CREATE TABLE IF NOT EXISTS "order" (
+    id             uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at     timestamptz NOT NULL DEFAULT now(),
+    updated_at     timestamptz NOT NULL DEFAULT now(),
+    business_id    uuid NOT NULL REFERENCES business (id),
+    created_by     uuid NOT NULL REFERENCES "user" (id),
+    status         text NOT NULL DEFAULT 'pending',
+    amount         numeric(19, 4) NOT NULL CHECK (amount > 0),
+    currency       char(3) NOT NULL,
+    description    text,
+    customer_email text
+);
+
+CREATE INDEX IF NOT EXISTS order_business_id_created_at_idx ON "order" (business_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS order_created_by_idx ON "order" (created_by);
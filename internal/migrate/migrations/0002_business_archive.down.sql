@@ -0,0 +1,3 @@
+ALTER TABLE business
+    DROP COLUMN archived_at,
+    DROP COLUMN created_by;
@@ -0,0 +1,3 @@
+ALTER TABLE business
+    ADD COLUMN created_by  uuid REFERENCES "user" (id),
+    ADD COLUMN archived_at timestamptz;
@@ -0,0 +1 @@
+ALTER TABLE business_user DROP COLUMN role;
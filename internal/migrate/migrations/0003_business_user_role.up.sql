@@ -0,0 +1,6 @@
+-- Existing memberships had unrestricted access, so they become owners.
+ALTER TABLE business_user
+    ADD COLUMN role text NOT NULL DEFAULT 'owner'
+        CHECK (role IN ('owner', 'admin', 'cashier', 'viewer'));
+
+ALTER TABLE business_user ALTER COLUMN role DROP DEFAULT;
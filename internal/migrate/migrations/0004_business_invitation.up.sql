@@ -0,0 +1,20 @@
+CREATE TABLE business_invitation (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    business_id uuid NOT NULL REFERENCES business (id) ON DELETE CASCADE,
+    email       text NOT NULL,
+    role        text NOT NULL CHECK (role IN ('owner', 'admin', 'cashier', 'viewer')),
+    status      text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'revoked')),
+    -- nonce is embedded in the signed token; rotating it on resend invalidates older tokens.
+    nonce       text NOT NULL,
+    invited_by  uuid NOT NULL REFERENCES "user" (id),
+    accepted_by uuid REFERENCES "user" (id),
+    expires_at  timestamptz NOT NULL,
+    accepted_at timestamptz,
+    revoked_at  timestamptz,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX business_invitation_pending_email_idx
+    ON business_invitation (business_id, lower(email))
+    WHERE status = 'pending';
@@ -0,0 +1,21 @@
+ALTER TABLE "order"
+    ADD CONSTRAINT order_status_check
+        CHECK (status IN ('pending', 'paid', 'fulfilled', 'cancelled', 'refunded', 'expired'));
+
+CREATE TABLE order_status_history (
+    id          bigserial PRIMARY KEY,
+    order_id    uuid NOT NULL REFERENCES "order" (id) ON DELETE CASCADE,
+    from_status text,
+    to_status   text NOT NULL,
+    actor_kind  text NOT NULL CHECK (actor_kind IN ('user', 'provider', 'system')),
+    actor_id    uuid REFERENCES "user" (id),
+    note        text,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX order_status_history_order_id_idx ON order_status_history (order_id, created_at);
+
+-- Seed the creation entry for orders that predate the history table.
+INSERT INTO order_status_history (order_id, from_status, to_status, actor_kind, actor_id, created_at)
+SELECT id, NULL, status, 'user', created_by, created_at
+FROM "order";
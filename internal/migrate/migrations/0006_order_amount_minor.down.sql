@@ -0,0 +1,15 @@
+ALTER TABLE "order" ADD COLUMN amount numeric(19, 4);
+
+UPDATE "order"
+SET amount = amount_minor / power(10, CASE
+    WHEN currency IN ('BIF', 'CLP', 'DJF', 'GNF', 'ISK', 'JPY', 'KMF', 'KRW',
+                      'PYG', 'RWF', 'UGX', 'UYI', 'VND', 'VUV', 'XAF', 'XOF', 'XPF') THEN 0
+    WHEN currency IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN 3
+    WHEN currency IN ('CLF', 'UYW') THEN 4
+    ELSE 2
+END)::numeric;
+
+ALTER TABLE "order"
+    ALTER COLUMN amount SET NOT NULL,
+    ADD CONSTRAINT order_amount_check CHECK (amount > 0),
+    DROP COLUMN amount_minor;
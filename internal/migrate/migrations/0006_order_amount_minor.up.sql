@@ -0,0 +1,37 @@
+-- Store order amounts as integer minor units of their currency instead of numeric.
+ALTER TABLE "order" ADD COLUMN amount_minor bigint, ADD COLUMN amount_exponent int;
+
+UPDATE "order"
+SET amount_exponent = CASE
+    WHEN currency IN ('BIF', 'CLP', 'DJF', 'GNF', 'ISK', 'JPY', 'KMF', 'KRW',
+                      'PYG', 'RWF', 'UGX', 'UYI', 'VND', 'VUV', 'XAF', 'XOF', 'XPF') THEN 0
+    WHEN currency IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND') THEN 3
+    WHEN currency IN ('CLF', 'UYW') THEN 4
+    ELSE 2
+END;
+
+-- An amount with more decimals than its currency allows has no exact minor-unit value. Refuse to
+-- migrate rather than round it, so the affected orders can be corrected by hand first.
+DO $$
+DECLARE
+    bad text;
+BEGIN
+    SELECT string_agg(format('%s (%s %s)', id, amount, currency), ', ' ORDER BY id)
+    INTO bad
+    FROM "order"
+    WHERE amount * 10::numeric ^ amount_exponent <> trunc(amount * 10::numeric ^ amount_exponent);
+    IF bad IS NOT NULL THEN
+        RAISE EXCEPTION 'orders have more decimals than their currency allows: %', bad
+            USING HINT = 'Correct these amounts, then run the migration again.';
+    END IF;
+END;
+$$;
+
+-- Scaled in numeric, not double precision, so large amounts convert exactly.
+UPDATE "order" SET amount_minor = (amount * 10::numeric ^ amount_exponent)::bigint;
+
+ALTER TABLE "order"
+    ALTER COLUMN amount_minor SET NOT NULL,
+    ADD CONSTRAINT order_amount_minor_check CHECK (amount_minor > 0),
+    DROP COLUMN amount,
+    DROP COLUMN amount_exponent;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS business_currency;
+ALTER TABLE business DROP COLUMN default_currency;
@@ -0,0 +1,13 @@
+ALTER TABLE business ADD COLUMN default_currency char(3) NOT NULL DEFAULT 'BZD';
+
+CREATE TABLE business_currency (
+    business_id uuid NOT NULL REFERENCES business (id) ON DELETE CASCADE,
+    currency    char(3) NOT NULL,
+    PRIMARY KEY (business_id, currency)
+);
+
+-- Every business starts with its default currency plus whatever its existing orders used.
+INSERT INTO business_currency (business_id, currency)
+SELECT id, default_currency FROM business
+UNION
+SELECT DISTINCT business_id, currency FROM "order";
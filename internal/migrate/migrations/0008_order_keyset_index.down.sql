@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS order_business_id_created_at_id_idx;
+CREATE INDEX order_business_id_created_at_idx ON "order" (business_id, created_at DESC);
@@ -0,0 +1,3 @@
+-- Keyset pagination on (created_at, id) within a business.
+DROP INDEX IF EXISTS order_business_id_created_at_idx;
+CREATE INDEX order_business_id_created_at_id_idx ON "order" (business_id, created_at DESC, id DESC);
@@ -0,0 +1,17 @@
+CREATE TABLE idempotency_key (
+    principal     text NOT NULL,
+    key           text NOT NULL,
+    method        text NOT NULL,
+    path          text NOT NULL,
+    fingerprint   bytea NOT NULL,
+    -- status_code is NULL while the original request is still being processed.
+    status_code   int,
+    content_type  text,
+    response_body bytea,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    completed_at  timestamptz,
+    expires_at    timestamptz NOT NULL,
+    PRIMARY KEY (principal, key)
+);
+
+CREATE INDEX idempotency_key_expires_at_idx ON idempotency_key (expires_at);
@@ -0,0 +1,25 @@
+CREATE TABLE payment (
+    id             uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id       uuid NOT NULL REFERENCES "order" (id) ON DELETE CASCADE,
+    business_id    uuid NOT NULL REFERENCES business (id) ON DELETE CASCADE,
+    provider       text NOT NULL,
+    -- provider_ref is NULL until the provider has accepted the intent.
+    provider_ref   text,
+    payment_method text,
+    status         text NOT NULL CHECK (status IN ('pending', 'processing', 'succeeded', 'failed')),
+    amount_minor   bigint NOT NULL CHECK (amount_minor > 0),
+    currency       char(3) NOT NULL,
+    fee_minor      bigint NOT NULL DEFAULT 0 CHECK (fee_minor >= 0),
+    failure_reason text,
+    created_by     uuid REFERENCES "user" (id),
+    created_at     timestamptz NOT NULL DEFAULT now(),
+    updated_at     timestamptz NOT NULL DEFAULT now(),
+    captured_at    timestamptz
+);
+
+CREATE UNIQUE INDEX payment_provider_ref_key ON payment (provider, provider_ref);
+CREATE INDEX payment_order_id_idx ON payment (order_id, created_at);
+
+-- At most one attempt per order may be in flight or succeeded; failed attempts can be retried.
+CREATE UNIQUE INDEX payment_order_active_key ON payment (order_id)
+    WHERE status IN ('pending', 'processing', 'succeeded');
@@ -0,0 +1,3 @@
+ALTER TABLE "order"
+    DROP COLUMN payment_link_expires_at,
+    DROP COLUMN payment_link_token;
@@ -0,0 +1,14 @@
+-- Every order gets an unguessable token for its public payment link (/pay/{token}).
+ALTER TABLE "order"
+    ADD COLUMN payment_link_token      text,
+    ADD COLUMN payment_link_expires_at timestamptz;
+
+UPDATE "order"
+SET payment_link_token      = 'pl_' || replace(gen_random_uuid()::text, '-', '') || replace(gen_random_uuid()::text, '-', ''),
+    payment_link_expires_at = now() + interval '7 days';
+
+ALTER TABLE "order"
+    ALTER COLUMN payment_link_token SET NOT NULL,
+    ALTER COLUMN payment_link_expires_at SET NOT NULL;
+
+CREATE UNIQUE INDEX order_payment_link_token_key ON "order" (payment_link_token);
@@ -0,0 +1,56 @@
+CREATE TABLE webhook_endpoint (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    business_id uuid NOT NULL REFERENCES business (id) ON DELETE CASCADE,
+    url         text NOT NULL,
+    description text,
+    -- secret signs deliveries; it has to be kept in clear text to compute signatures.
+    secret      text NOT NULL,
+    -- event_types is a JSON array of subscribed event type names.
+    event_types jsonb NOT NULL CHECK (jsonb_typeof(event_types) = 'array'),
+    active      boolean NOT NULL DEFAULT true,
+    created_by  uuid REFERENCES "user" (id),
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX webhook_endpoint_business_id_idx ON webhook_endpoint (business_id);
+
+CREATE TABLE webhook_event (
+    id          uuid PRIMARY KEY,
+    business_id uuid NOT NULL REFERENCES business (id) ON DELETE CASCADE,
+    type        text NOT NULL,
+    payload     jsonb NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE webhook_delivery (
+    id              uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id        uuid NOT NULL REFERENCES webhook_event (id) ON DELETE CASCADE,
+    endpoint_id     uuid NOT NULL REFERENCES webhook_endpoint (id) ON DELETE CASCADE,
+    status          text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts        int NOT NULL DEFAULT 0,
+    -- next_attempt_at doubles as a lease while a dispatcher is sending the delivery.
+    next_attempt_at timestamptz NOT NULL DEFAULT now(),
+    last_attempt_at timestamptz,
+    response_status int,
+    last_error      text,
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    completed_at    timestamptz
+);
+
+CREATE INDEX webhook_delivery_due_idx ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_delivery_endpoint_idx ON webhook_delivery (endpoint_id, created_at DESC);
+
+CREATE TABLE webhook_delivery_attempt (
+    id              bigserial PRIMARY KEY,
+    delivery_id     uuid NOT NULL REFERENCES webhook_delivery (id) ON DELETE CASCADE,
+    attempt         int NOT NULL,
+    response_status int,
+    -- response_body is truncated to a few KiB.
+    response_body   text,
+    error           text,
+    duration_ms     int NOT NULL,
+    created_at      timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX webhook_delivery_attempt_delivery_idx ON webhook_delivery_attempt (delivery_id, attempt);
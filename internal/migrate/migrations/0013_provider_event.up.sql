@@ -0,0 +1,18 @@
+-- Inbound provider webhooks, stored once per provider event ID so redelivered events are ignored.
+CREATE TABLE provider_event (
+    id            bigserial PRIMARY KEY,
+    provider      text NOT NULL,
+    event_id      text NOT NULL,
+    type          text NOT NULL,
+    payment_ref   text,
+    refund_ref    text,
+    payload       bytea NOT NULL,
+    -- review marks events that could not be applied automatically and need a human.
+    status        text NOT NULL CHECK (status IN ('processed', 'ignored', 'review')),
+    review_reason text,
+    order_id      uuid REFERENCES "order" (id) ON DELETE SET NULL,
+    received_at   timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (provider, event_id)
+);
+
+CREATE INDEX provider_event_review_idx ON provider_event (received_at) WHERE status = 'review';
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS refund;
+
+UPDATE "order" SET status = 'refunded' WHERE status = 'partially_refunded';
+ALTER TABLE "order" DROP CONSTRAINT order_status_check;
+ALTER TABLE "order"
+    ADD CONSTRAINT order_status_check
+        CHECK (status IN ('pending', 'paid', 'fulfilled', 'cancelled', 'refunded', 'expired'));
@@ -0,0 +1,28 @@
+ALTER TABLE "order" DROP CONSTRAINT order_status_check;
+ALTER TABLE "order"
+    ADD CONSTRAINT order_status_check
+        CHECK (status IN ('pending', 'paid', 'fulfilled', 'cancelled', 'refunded', 'partially_refunded', 'expired'));
+
+CREATE TABLE refund (
+    id             uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id       uuid NOT NULL REFERENCES "order" (id) ON DELETE CASCADE,
+    payment_id     uuid NOT NULL REFERENCES payment (id) ON DELETE CASCADE,
+    business_id    uuid NOT NULL REFERENCES business (id) ON DELETE CASCADE,
+    provider       text NOT NULL,
+    -- provider_ref is NULL until the provider has accepted the refund.
+    provider_ref   text,
+    -- pending refunds count against the refundable amount until they fail.
+    status         text NOT NULL CHECK (status IN ('pending', 'succeeded', 'failed')),
+    amount_minor   bigint NOT NULL CHECK (amount_minor > 0),
+    currency       char(3) NOT NULL,
+    reason         text NOT NULL CHECK (length(btrim(reason)) > 0),
+    failure_reason text,
+    created_by     uuid REFERENCES "user" (id),
+    created_at     timestamptz NOT NULL DEFAULT now(),
+    updated_at     timestamptz NOT NULL DEFAULT now(),
+    refunded_at    timestamptz
+);
+
+CREATE UNIQUE INDEX refund_provider_ref_key ON refund (provider, provider_ref);
+CREATE INDEX refund_order_id_idx ON refund (order_id, created_at);
+CREATE INDEX refund_business_keyset_idx ON refund (business_id, created_at DESC, id DESC);
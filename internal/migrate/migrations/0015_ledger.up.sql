@@ -0,0 +1,111 @@
+-- Double-entry ledger. Amounts are signed: debits are positive and credits negative,
+-- so the entries of every transaction sum to zero.
+CREATE TABLE ledger_account (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    business_id uuid NOT NULL REFERENCES business (id),
+    code        text NOT NULL CHECK (code IN ('pending', 'available', 'fees', 'revenue', 'refunds')),
+    currency    char(3) NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (business_id, code, currency)
+);
+
+CREATE TABLE ledger_transaction (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    business_id uuid NOT NULL REFERENCES business (id),
+    kind        text NOT NULL CHECK (kind IN ('payment', 'fee', 'refund')),
+    currency    char(3) NOT NULL,
+    order_id    uuid REFERENCES "order" (id),
+    payment_id  uuid REFERENCES payment (id),
+    refund_id   uuid REFERENCES refund (id),
+    description text,
+    posted_at   timestamptz NOT NULL DEFAULT now()
+);
+
+-- A payment or refund is posted at most once per kind.
+CREATE UNIQUE INDEX ledger_transaction_payment_key ON ledger_transaction (payment_id, kind)
+    WHERE refund_id IS NULL;
+CREATE UNIQUE INDEX ledger_transaction_refund_key ON ledger_transaction (refund_id)
+    WHERE refund_id IS NOT NULL;
+CREATE INDEX ledger_transaction_business_idx ON ledger_transaction (business_id, posted_at);
+
+CREATE TABLE ledger_entry (
+    id             bigserial PRIMARY KEY,
+    transaction_id uuid NOT NULL REFERENCES ledger_transaction (id),
+    account_id     uuid NOT NULL REFERENCES ledger_account (id),
+    amount_minor   bigint NOT NULL CHECK (amount_minor <> 0)
+);
+
+CREATE INDEX ledger_entry_transaction_idx ON ledger_entry (transaction_id);
+CREATE INDEX ledger_entry_account_idx ON ledger_entry (account_id);
+
+-- Posted transactions are never changed; corrections are posted as new transactions.
+CREATE FUNCTION ledger_reject_change() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger is append-only: % on % rejected', TG_OP, TG_TABLE_NAME;
+END;
+$$;
+
+CREATE TRIGGER ledger_transaction_append_only BEFORE UPDATE OR DELETE ON ledger_transaction
+    FOR EACH ROW EXECUTE FUNCTION ledger_reject_change();
+CREATE TRIGGER ledger_entry_append_only BEFORE UPDATE OR DELETE ON ledger_entry
+    FOR EACH ROW EXECUTE FUNCTION ledger_reject_change();
+
+-- Checked at commit so every leg of a transaction can be inserted first.
+CREATE FUNCTION ledger_check_balanced() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+    IF (SELECT sum(amount_minor) FROM ledger_entry WHERE transaction_id = NEW.transaction_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger transaction % does not balance', NEW.transaction_id;
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+CREATE CONSTRAINT TRIGGER ledger_entry_balanced AFTER INSERT ON ledger_entry
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_check_balanced();
+
+-- Post the payments, fees and refunds that predate the ledger.
+INSERT INTO ledger_account (business_id, code, currency)
+SELECT DISTINCT p.business_id, c.code, p.currency
+FROM payment p
+CROSS JOIN (VALUES ('pending'), ('available'), ('fees'), ('revenue'), ('refunds')) AS c (code)
+WHERE p.status = 'succeeded';
+
+WITH t AS (
+    INSERT INTO ledger_transaction (business_id, kind, currency, order_id, payment_id, description, posted_at)
+    SELECT business_id, 'payment', currency, order_id, id, 'payment ' || id, COALESCE(captured_at, updated_at)
+    FROM payment
+    WHERE status = 'succeeded'
+    RETURNING id, business_id, currency, payment_id
+)
+INSERT INTO ledger_entry (transaction_id, account_id, amount_minor)
+SELECT t.id, a.id, CASE a.code WHEN 'pending' THEN p.amount_minor ELSE -p.amount_minor END
+FROM t
+JOIN payment p ON p.id = t.payment_id
+JOIN ledger_account a ON a.business_id = t.business_id AND a.currency = t.currency AND a.code IN ('pending', 'revenue');
+
+WITH t AS (
+    INSERT INTO ledger_transaction (business_id, kind, currency, order_id, payment_id, description, posted_at)
+    SELECT business_id, 'fee', currency, order_id, id, 'fee for payment ' || id, COALESCE(captured_at, updated_at)
+    FROM payment
+    WHERE status = 'succeeded' AND fee_minor > 0
+    RETURNING id, business_id, currency, payment_id
+)
+INSERT INTO ledger_entry (transaction_id, account_id, amount_minor)
+SELECT t.id, a.id, CASE a.code WHEN 'fees' THEN p.fee_minor ELSE -p.fee_minor END
+FROM t
+JOIN payment p ON p.id = t.payment_id
+JOIN ledger_account a ON a.business_id = t.business_id AND a.currency = t.currency AND a.code IN ('fees', 'pending');
+
+WITH t AS (
+    INSERT INTO ledger_transaction (business_id, kind, currency, order_id, payment_id, refund_id, description, posted_at)
+    SELECT business_id, 'refund', currency, order_id, payment_id, id, 'refund ' || id, COALESCE(refunded_at, updated_at)
+    FROM refund
+    WHERE status = 'succeeded'
+    RETURNING id, business_id, currency, refund_id
+)
+INSERT INTO ledger_entry (transaction_id, account_id, amount_minor)
+SELECT t.id, a.id, CASE a.code WHEN 'refunds' THEN rf.amount_minor ELSE -rf.amount_minor END
+FROM t
+JOIN refund rf ON rf.id = t.refund_id
+JOIN ledger_account a ON a.business_id = t.business_id AND a.currency = t.currency AND a.code IN ('refunds', 'pending');
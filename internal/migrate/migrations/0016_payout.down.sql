@@ -0,0 +1,28 @@
+DROP TABLE IF EXISTS payout_item;
+
+-- The ledger is append-only, so its payout rows are removed with the triggers disabled.
+ALTER TABLE ledger_entry DISABLE TRIGGER USER;
+ALTER TABLE ledger_transaction DISABLE TRIGGER USER;
+DELETE FROM ledger_entry
+WHERE transaction_id IN (SELECT id FROM ledger_transaction WHERE kind IN ('settlement', 'payout'));
+DELETE FROM ledger_transaction WHERE kind IN ('settlement', 'payout');
+DELETE FROM ledger_entry
+WHERE account_id IN (SELECT id FROM ledger_account WHERE code = 'payouts');
+DELETE FROM ledger_account WHERE code = 'payouts';
+ALTER TABLE ledger_entry ENABLE TRIGGER USER;
+ALTER TABLE ledger_transaction ENABLE TRIGGER USER;
+
+ALTER TABLE ledger_transaction DROP COLUMN IF EXISTS payout_id;
+ALTER TABLE ledger_transaction DROP CONSTRAINT ledger_transaction_kind_check;
+ALTER TABLE ledger_transaction
+    ADD CONSTRAINT ledger_transaction_kind_check
+        CHECK (kind IN ('payment', 'fee', 'refund'));
+ALTER TABLE ledger_account DROP CONSTRAINT ledger_account_code_check;
+ALTER TABLE ledger_account
+    ADD CONSTRAINT ledger_account_code_check
+        CHECK (code IN ('pending', 'available', 'fees', 'revenue', 'refunds'));
+
+DROP TABLE IF EXISTS payout;
+DROP INDEX IF EXISTS payment_unsettled_idx;
+ALTER TABLE payment DROP COLUMN IF EXISTS settled_at;
+ALTER TABLE business DROP COLUMN IF EXISTS payout_schedule;
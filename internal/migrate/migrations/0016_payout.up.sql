@@ -0,0 +1,53 @@
+ALTER TABLE business
+    ADD COLUMN payout_schedule text NOT NULL DEFAULT 'daily'
+        CHECK (payout_schedule IN ('daily', 'weekly', 'manual'));
+
+-- settled_at is set once the payment's net amount has moved from pending to available.
+ALTER TABLE payment ADD COLUMN settled_at timestamptz;
+
+CREATE INDEX payment_unsettled_idx ON payment (captured_at)
+    WHERE status = 'succeeded' AND settled_at IS NULL;
+
+CREATE TABLE payout (
+    id             uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    business_id    uuid NOT NULL REFERENCES business (id),
+    currency       char(3) NOT NULL,
+    trigger        text NOT NULL CHECK (trigger IN ('schedule', 'manual')),
+    -- gross - fees - refunded = amount, summed over the items.
+    gross_minor    bigint NOT NULL,
+    fee_minor      bigint NOT NULL,
+    refunded_minor bigint NOT NULL,
+    amount_minor   bigint NOT NULL CHECK (amount_minor > 0),
+    created_by     uuid REFERENCES "user" (id),
+    created_at     timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX payout_business_keyset_idx ON payout (business_id, created_at DESC, id DESC);
+
+ALTER TABLE ledger_account DROP CONSTRAINT ledger_account_code_check;
+ALTER TABLE ledger_account
+    ADD CONSTRAINT ledger_account_code_check
+        CHECK (code IN ('pending', 'available', 'fees', 'revenue', 'refunds', 'payouts'));
+
+ALTER TABLE ledger_transaction DROP CONSTRAINT ledger_transaction_kind_check;
+ALTER TABLE ledger_transaction
+    ADD CONSTRAINT ledger_transaction_kind_check
+        CHECK (kind IN ('payment', 'fee', 'refund', 'settlement', 'payout'));
+ALTER TABLE ledger_transaction ADD COLUMN payout_id uuid REFERENCES payout (id);
+
+-- One line per ledger transaction that moved funds into or out of the available account:
+-- a payment's settlement, or a refund made after it settled.
+CREATE TABLE payout_item (
+    id                    bigserial PRIMARY KEY,
+    payout_id             uuid NOT NULL REFERENCES payout (id),
+    ledger_transaction_id uuid NOT NULL UNIQUE REFERENCES ledger_transaction (id),
+    order_id              uuid NOT NULL REFERENCES "order" (id),
+    payment_id            uuid NOT NULL REFERENCES payment (id),
+    refund_id             uuid REFERENCES refund (id),
+    gross_minor           bigint NOT NULL,
+    fee_minor             bigint NOT NULL,
+    refunded_minor        bigint NOT NULL,
+    amount_minor          bigint NOT NULL
+);
+
+CREATE INDEX payout_item_payout_id_idx ON payout_item (payout_id, id);
@@ -0,0 +1,10 @@
+ALTER TABLE "order" ADD COLUMN customer_email text;
+
+UPDATE "order" o
+SET customer_email = c.email
+FROM customer c
+WHERE c.id = o.customer_id;
+
+DROP INDEX IF EXISTS order_customer_keyset_idx;
+ALTER TABLE "order" DROP COLUMN IF EXISTS customer_id;
+DROP TABLE IF EXISTS customer;
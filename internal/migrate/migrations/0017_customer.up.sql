@@ -0,0 +1,34 @@
+CREATE TABLE customer (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    business_id uuid NOT NULL REFERENCES business (id) ON DELETE CASCADE,
+    name        text,
+    email       text,
+    phone       text,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    CHECK (name IS NOT NULL OR email IS NOT NULL OR phone IS NOT NULL)
+);
+
+-- Orders created with an email reuse the business's customer with that email.
+CREATE UNIQUE INDEX customer_business_email_key ON customer (business_id, lower(email))
+    WHERE email IS NOT NULL;
+CREATE INDEX customer_business_keyset_idx ON customer (business_id, created_at DESC, id DESC);
+
+ALTER TABLE "order" ADD COLUMN customer_id uuid REFERENCES customer (id);
+CREATE INDEX order_customer_keyset_idx ON "order" (customer_id, created_at DESC, id DESC)
+    WHERE customer_id IS NOT NULL;
+
+-- One customer per business and distinct email; the earliest order dates the customer.
+INSERT INTO customer (business_id, email, created_at, updated_at)
+SELECT business_id, lower(btrim(customer_email)), min(created_at), min(created_at)
+FROM "order"
+WHERE btrim(COALESCE(customer_email, '')) <> ''
+GROUP BY business_id, lower(btrim(customer_email));
+
+UPDATE "order" o
+SET customer_id = c.id
+FROM customer c
+WHERE c.business_id = o.business_id
+    AND c.email = lower(btrim(o.customer_email));
+
+ALTER TABLE "order" DROP COLUMN customer_email;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notification;
+ALTER TABLE "user" DROP COLUMN IF EXISTS email;
@@ -0,0 +1,24 @@
+-- Members' email addresses, used for business alerts. Recorded on registration and when an
+-- invitation is accepted; older accounts have none until then.
+ALTER TABLE "user" ADD COLUMN email text;
+
+-- notification is the outbox of transactional emails. Messages are rendered when they are
+-- queued so that a retry sends exactly what the triggering change produced.
+CREATE TABLE notification (
+    id              uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    business_id     uuid NOT NULL REFERENCES business (id) ON DELETE CASCADE,
+    template        text NOT NULL,
+    recipient       text NOT NULL,
+    subject         text NOT NULL,
+    html            text NOT NULL,
+    status          text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts        int NOT NULL DEFAULT 0,
+    -- next_attempt_at doubles as a lease while a sender is delivering the message.
+    next_attempt_at timestamptz NOT NULL DEFAULT now(),
+    last_error      text,
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    sent_at         timestamptz
+);
+
+CREATE INDEX notification_due_idx ON notification (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX notification_business_idx ON notification (business_id, created_at DESC);
@@ -0,0 +1,19 @@
+CREATE TABLE api_key (
+    id           uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    business_id  uuid NOT NULL REFERENCES business (id) ON DELETE CASCADE,
+    name         text NOT NULL,
+    -- prefix is the start of the key, kept so members can tell keys apart.
+    prefix       text NOT NULL,
+    -- key_hash is the SHA-256 of the key; the key itself is only shown when it is issued.
+    key_hash     bytea NOT NULL UNIQUE,
+    -- scopes is a JSON array of scope names.
+    scopes       jsonb NOT NULL CHECK (jsonb_typeof(scopes) = 'array'),
+    -- Requests made with the key act as this member.
+    created_by   uuid NOT NULL REFERENCES "user" (id),
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    updated_at   timestamptz NOT NULL DEFAULT now(),
+    last_used_at timestamptz,
+    revoked_at   timestamptz
+);
+
+CREATE INDEX api_key_business_idx ON api_key (business_id, created_at DESC);
@@ -0,0 +1,8 @@
+UPDATE provider_event SET status = 'review' WHERE status = 'resolved';
+ALTER TABLE provider_event
+    DROP COLUMN resolution_note,
+    DROP COLUMN resolved_by,
+    DROP COLUMN resolved_at,
+    DROP CONSTRAINT provider_event_status_check;
+ALTER TABLE provider_event
+    ADD CONSTRAINT provider_event_status_check CHECK (status IN ('processed', 'ignored', 'review'));
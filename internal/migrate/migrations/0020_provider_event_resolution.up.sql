@@ -0,0 +1,7 @@
+-- Operators work the review queue through /api/provider-events and resolve entries with a note.
+ALTER TABLE provider_event DROP CONSTRAINT provider_event_status_check;
+ALTER TABLE provider_event
+    ADD CONSTRAINT provider_event_status_check CHECK (status IN ('processed', 'ignored', 'review', 'resolved')),
+    ADD COLUMN resolved_at     timestamptz,
+    ADD COLUMN resolved_by     uuid REFERENCES "user" (id),
+    ADD COLUMN resolution_note text;
@@ -0,0 +1,104 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/business"
+	"backend/internal/model/businessuser"
+)
+
+// APIKeyPayload is the body accepted by POST /api/api-keys.
+type APIKeyPayload struct {
+	BusinessID string               `json:"business_id"`
+	Name       string               `json:"name" example:"Front desk POS"`
+	Scopes     []businessuser.Scope `json:"scopes" example:"orders:read,orders:write"`
+}
+
+// attachCreateRoutes registers the create (POST) endpoint.
+func attachCreateRoutes(r chi.Router, db *sql.DB) {
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) { createAPIKey(db, w, r) })
+}
+
+// createAPIKey handles POST /api/api-keys
+//
+// @Summary      Create an API key
+// @Description  Issues a key for server-to-server access to one business, sent as Authorization: Bearer pk_.... Requests made with it act as the creating member, limited to the key's scopes: orders:read, orders:write, refunds:write, customers:read, customers:write, finance:read. Keys stop working if the creator leaves the business. The key is only shown once.
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      APIKeyPayload  true  "API key payload"
+// @Success      201      {object}  IssuedAPIKey
+// @Failure      400      {object}  ErrorResponse
+// @Failure      403      {object}  businessuser.MissingPermissionResponse
+// @Failure      409      {object}  ErrorResponse  "Business is archived"
+// @Router       /api/api-keys [post]
+func createAPIKey(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p APIKeyPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		httpx.WriteBadRequest(w)
+		return
+	}
+	p.BusinessID = strings.TrimSpace(p.BusinessID)
+	if p.BusinessID == "" {
+		httpx.WriteErr(w, http.StatusBadRequest, "business_id is required")
+		return
+	}
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		httpx.WriteErr(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	scopes, err := validateScopes(p.Scopes)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger := slog.Default().With(
+		slog.String("component", "api_keys"),
+		slog.String("op", "createAPIKey"),
+		slog.String("business_id", p.BusinessID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if !businessuser.AssertPermission(ctx, db, w, p.BusinessID, u, businessuser.PermBusinessManage) {
+		return
+	}
+	if !business.AssertActive(ctx, db, w, p.BusinessID) {
+		return
+	}
+
+	key, prefix := newKey()
+	k, err := scanAPIKey(db.QueryRowContext(ctx, `
+		INSERT INTO api_key (business_id, name, prefix, key_hash, scopes, created_by)
+		VALUES ($1, $2, $3, $4, $5::jsonb, (SELECT id FROM "user" WHERE firebase_id = $6))
+		RETURNING `+apiKeyColumns,
+		p.BusinessID, p.Name, prefix, auth.HashAPIKey(key), scopes, u.UID,
+	))
+	if err != nil {
+		logger.Error("insert api key failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusCreated, IssuedAPIKey{APIKey: k, Key: key})
+}
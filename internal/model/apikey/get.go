@@ -0,0 +1,143 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+)
+
+// attachGetRoutes registers the list and single-key (GET) endpoints.
+func attachGetRoutes(r chi.Router, db *sql.DB) {
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) { listAPIKeys(db, w, r) })
+	r.Get("/{keyID}", func(w http.ResponseWriter, r *http.Request) { getAPIKey(db, w, r) })
+}
+
+// listAPIKeys handles GET /api/api-keys?business_id=...
+//
+// @Summary      List API keys
+// @Description  Returns the API keys of a business, newest first, including revoked ones. Keys themselves are not included.
+// @Tags         api-keys
+// @Produce      json
+// @Param        business_id  query     string  true  "Business ID"
+// @Success      200          {array}   APIKey
+// @Failure      400          {object}  ErrorResponse
+// @Failure      403          {object}  businessuser.MissingPermissionResponse
+// @Router       /api/api-keys [get]
+func listAPIKeys(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	bizID := strings.TrimSpace(r.URL.Query().Get("business_id"))
+	if bizID == "" {
+		httpx.WriteErr(w, http.StatusBadRequest, "business_id is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if !businessuser.AssertPermission(ctx, db, w, bizID, u, businessuser.PermBusinessManage) {
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_key
+		WHERE business_id = $1::uuid
+		ORDER BY created_at DESC`,
+		bizID,
+	)
+	if err != nil {
+		slog.Error("query api keys failed", slog.String("business_id", bizID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+
+	keys := make([]APIKey, 0)
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			slog.Error("scan api key row failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error("rows error after iteration", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(keys)
+}
+
+// getAPIKey handles GET /api/api-keys/{keyID}
+//
+// @Summary      Get an API key
+// @Tags         api-keys
+// @Produce      json
+// @Param        keyID  path      string  true  "API key ID"
+// @Success      200    {object}  APIKey
+// @Failure      403    {object}  businessuser.MissingPermissionResponse
+// @Failure      404    {object}  ErrorResponse
+// @Router       /api/api-keys/{keyID} [get]
+func getAPIKey(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	k, ok := loadAPIKeyForAdmin(ctx, db, w, chi.URLParam(r, "keyID"), u)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(k)
+}
+
+// loadAPIKeyForAdmin fetches a key and checks the caller may manage its business.
+// Returns false after writing 404, 403 or 500.
+func loadAPIKeyForAdmin(ctx context.Context, db *sql.DB, w http.ResponseWriter, keyID string, u *auth.User) (APIKey, bool) {
+	if _, err := uuid.Parse(keyID); err != nil {
+		httpx.WriteErr(w, http.StatusNotFound, "api key not found")
+		return APIKey{}, false
+	}
+	k, err := scanAPIKey(db.QueryRowContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_key WHERE id = $1`, keyID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusNotFound, "api key not found")
+		return APIKey{}, false
+	} else if err != nil {
+		slog.Error("query api key failed", slog.String("api_key_id", keyID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return APIKey{}, false
+	}
+	if !businessuser.AssertPermission(ctx, db, w, k.BusinessID, u, businessuser.PermBusinessManage) {
+		return APIKey{}, false
+	}
+	return k, true
+}
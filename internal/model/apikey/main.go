@@ -0,0 +1,19 @@
+package apikey
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Routes aggregates all API key submodule routes (create, list, rotate, revoke).
+// They must be mounted behind Firebase authentication only: keys cannot manage keys.
+func Routes(db *sql.DB) http.Handler {
+	r := chi.NewRouter()
+	attachCreateRoutes(r, db)
+	attachGetRoutes(r, db)
+	attachRotateRoutes(r, db)
+	attachRevokeRoutes(r, db)
+	return r
+}
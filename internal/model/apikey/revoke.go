@@ -0,0 +1,65 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+)
+
+// attachRevokeRoutes registers the revoke (DELETE) endpoint.
+func attachRevokeRoutes(r chi.Router, db *sql.DB) {
+	r.Delete("/{keyID}", func(w http.ResponseWriter, r *http.Request) { revokeAPIKey(db, w, r) })
+}
+
+// revokeAPIKey handles DELETE /api/api-keys/{keyID}
+//
+// @Summary      Revoke an API key
+// @Description  Revokes the key so requests made with it are rejected. The key stays listed with its revoked_at time.
+// @Tags         api-keys
+// @Produce      json
+// @Param        keyID  path      string  true  "API key ID"
+// @Success      200    {object}  APIKey
+// @Failure      403    {object}  businessuser.MissingPermissionResponse
+// @Failure      404    {object}  ErrorResponse
+// @Failure      409    {object}  ErrorResponse  "API key is already revoked"
+// @Router       /api/api-keys/{keyID} [delete]
+func revokeAPIKey(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	cur, ok := loadAPIKeyForAdmin(ctx, db, w, chi.URLParam(r, "keyID"), u)
+	if !ok {
+		return
+	}
+
+	k, err := scanAPIKey(db.QueryRowContext(ctx, `
+		UPDATE api_key SET revoked_at = now(), updated_at = now()
+		WHERE id = $1 AND revoked_at IS NULL
+		RETURNING `+apiKeyColumns,
+		cur.ID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusConflict, "api key is already revoked")
+		return
+	} else if err != nil {
+		slog.Error("revoke api key failed", slog.String("api_key_id", cur.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, k)
+}
@@ -0,0 +1,66 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+)
+
+// attachRotateRoutes registers the rotate endpoint.
+func attachRotateRoutes(r chi.Router, db *sql.DB) {
+	r.Post("/{keyID}/rotate", func(w http.ResponseWriter, r *http.Request) { rotateAPIKey(db, w, r) })
+}
+
+// rotateAPIKey handles POST /api/api-keys/{keyID}/rotate
+//
+// @Summary      Rotate an API key
+// @Description  Issues a new key for an active API key, keeping its ID, name and scopes. The previous key stops working immediately.
+// @Tags         api-keys
+// @Produce      json
+// @Param        keyID  path      string  true  "API key ID"
+// @Success      200    {object}  IssuedAPIKey
+// @Failure      403    {object}  businessuser.MissingPermissionResponse
+// @Failure      404    {object}  ErrorResponse
+// @Failure      409    {object}  ErrorResponse  "API key is revoked"
+// @Router       /api/api-keys/{keyID}/rotate [post]
+func rotateAPIKey(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	cur, ok := loadAPIKeyForAdmin(ctx, db, w, chi.URLParam(r, "keyID"), u)
+	if !ok {
+		return
+	}
+
+	key, prefix := newKey()
+	k, err := scanAPIKey(db.QueryRowContext(ctx, `
+		UPDATE api_key SET prefix = $2, key_hash = $3, last_used_at = NULL, updated_at = now()
+		WHERE id = $1 AND revoked_at IS NULL
+		RETURNING `+apiKeyColumns,
+		cur.ID, prefix, auth.HashAPIKey(key),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusConflict, "api key is revoked")
+		return
+	} else if err != nil {
+		slog.Error("rotate api key failed", slog.String("api_key_id", cur.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, IssuedAPIKey{APIKey: k, Key: key})
+}
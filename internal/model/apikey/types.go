@@ -0,0 +1,83 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/auth"
+	"backend/internal/model/businessuser"
+)
+
+// APIKey is a business credential for server-to-server access. The key itself is never stored.
+type APIKey struct {
+	ID         string               `json:"id"`
+	BusinessID string               `json:"business_id"`
+	Name       string               `json:"name"`
+	Prefix     string               `json:"prefix" example:"pk_3f9a1c2b"` // start of the key, for telling keys apart
+	Scopes     []businessuser.Scope `json:"scopes"`
+	CreatedBy  string               `json:"created_by"` // requests made with the key act as this member
+	CreatedAt  time.Time            `json:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+	LastUsedAt *time.Time           `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time           `json:"revoked_at,omitempty"`
+}
+
+// IssuedAPIKey is returned whenever a key is minted (create and rotate).
+// The key is not stored and cannot be retrieved again.
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key" example:"pk_3f9a1c2b..."`
+}
+
+// apiKeyColumns is the column list scanned by scanAPIKey.
+const apiKeyColumns = `id, business_id, name, prefix, scopes, created_by, created_at, updated_at, last_used_at, revoked_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row rowScanner) (APIKey, error) {
+	var k APIKey
+	var scopes []byte
+	if err := row.Scan(&k.ID, &k.BusinessID, &k.Name, &k.Prefix, &scopes, &k.CreatedBy, &k.CreatedAt, &k.UpdatedAt, &k.LastUsedAt, &k.RevokedAt); err != nil {
+		return APIKey{}, err
+	}
+	if err := json.Unmarshal(scopes, &k.Scopes); err != nil {
+		return APIKey{}, err
+	}
+	return k, nil
+}
+
+// newKey returns a fresh key carrying 256 bits of randomness and its display prefix.
+func newKey() (key, prefix string) {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	key = auth.APIKeyPrefix + hex.EncodeToString(b)
+	return key, key[:len(auth.APIKeyPrefix)+8]
+}
+
+// validateScopes checks every scope is known and returns the deduplicated list as a JSON array.
+func validateScopes(scopes []businessuser.Scope) (string, error) {
+	if len(scopes) == 0 {
+		return "", errors.New("scopes must not be empty")
+	}
+	seen := make(map[businessuser.Scope]bool, len(scopes))
+	out := make([]businessuser.Scope, 0, len(scopes))
+	for _, s := range scopes {
+		sc, ok := businessuser.ParseScope(strings.TrimSpace(string(s)))
+		if !ok {
+			return "", fmt.Errorf("unknown scope %q", s)
+		}
+		if !seen[sc] {
+			seen[sc] = true
+			out = append(out, sc)
+		}
+	}
+	b, err := json.Marshal(out)
+	return string(b), err
+}
@@ -0,0 +1,37 @@
+package business
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+
+	"backend/internal/httpx"
+)
+
+// AssertActive checks that the business exists and has not been archived.
+// It writes 404 for unknown businesses and 409 for archived ones.
+// Returns true if the request may proceed; false otherwise (an error response has been written).
+func AssertActive(ctx context.Context, db *sql.DB, w http.ResponseWriter, businessID string) bool {
+	var archived bool
+	err := db.QueryRowContext(ctx,
+		`SELECT archived_at IS NOT NULL FROM business WHERE id = $1::uuid`, businessID,
+	).Scan(&archived)
+	if err == sql.ErrNoRows {
+		httpx.WriteErr(w, http.StatusNotFound, "business not found")
+		return false
+	} else if err != nil {
+		slog.Default().With(
+			slog.String("component", "business"),
+			slog.String("op", "AssertActive"),
+			slog.String("business_id", businessID),
+		).Error("business lookup failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return false
+	}
+	if archived {
+		httpx.WriteErr(w, http.StatusConflict, "business is archived")
+		return false
+	}
+	return true
+}
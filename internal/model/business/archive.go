@@ -0,0 +1,70 @@
+package business
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+)
+
+// attachArchiveRoutes registers the soft-archive endpoint.
+func attachArchiveRoutes(r chi.Router, db *sql.DB) {
+	r.Post("/{businessID}/archive", func(w http.ResponseWriter, r *http.Request) { archiveBusiness(db, w, r) })
+}
+
+// archiveBusiness handles POST /api/businesses/{businessID}/archive
+//
+// @Summary      Archive a business
+// @Description  Soft-archives a business. Its data stays readable but no new orders can be created. Archiving an archived business is a no-op.
+// @Tags         businesses
+// @Produce      json
+// @Param        businessID  path      string  true  "Business ID"
+// @Success      200         {object}  Business
+// @Failure      403         {object}  businessuser.MissingPermissionResponse
+// @Failure      404         {object}  ErrorResponse
+// @Router       /api/businesses/{businessID}/archive [post]
+func archiveBusiness(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	businessID, ok := businessIDParam(w, r)
+	if !ok {
+		return
+	}
+	if !businessuser.AssertPermission(ctx, db, w, businessID, u, businessuser.PermBusinessArchive) {
+		return
+	}
+
+	if _, err := db.ExecContext(ctx, `
+		UPDATE business
+		SET archived_at = now(), updated_at = now()
+		WHERE id = $1::uuid AND archived_at IS NULL`,
+		businessID,
+	); err != nil {
+		slog.Error("archive business failed", slog.String("business_id", businessID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	b, ok := loadBusiness(ctx, db, w, businessID)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(b)
+}
@@ -0,0 +1,163 @@
+package business
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+)
+
+// BusinessPayload is the body accepted by the create and update endpoints.
+// Omitted fields keep their current value on update. New businesses default to BZD
+// and daily payouts.
+type BusinessPayload struct {
+	Name            *string  `json:"name"`
+	DefaultCurrency *string  `json:"default_currency" example:"BZD"`
+	Currencies      []string `json:"currencies" example:"BZD,USD"`
+	PayoutSchedule  *string  `json:"payout_schedule" example:"daily"`
+}
+
+// attachCreateRoutes registers the create (POST) endpoint.
+func attachCreateRoutes(r chi.Router, db *sql.DB) {
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) { createBusiness(db, w, r) })
+}
+
+// createBusiness handles POST /api/businesses
+//
+// @Summary      Create a business
+// @Description  Creates a business and makes the authenticated user its owner
+// @Tags         businesses
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      BusinessPayload  true  "Business payload"
+// @Success      201      {object}  Business
+// @Failure      400      {object}  ErrorResponse
+// @Router       /api/businesses [post]
+func createBusiness(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p BusinessPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		httpx.WriteBadRequest(w)
+		return
+	}
+	name, err := validateName(p.Name)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defCurrency, currencies, err := settleCurrencies(p, defaultCurrency, nil)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	schedule, err := validatePayoutSchedule(p.PayoutSchedule, PayoutDaily)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger := slog.Default().With(
+		slog.String("component", "businesses"),
+		slog.String("op", "createBusiness"),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("begin tx failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var userID string
+	err = tx.QueryRowContext(ctx, `SELECT id FROM "user" WHERE firebase_id = $1`, u.UID).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusBadRequest, "user not initialized")
+		return
+	} else if err != nil {
+		logger.Error("query user failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	b, err := scanBusiness(tx.QueryRowContext(ctx,
+		`INSERT INTO business (name, created_by, default_currency, payout_schedule) VALUES ($1, $2, $3, $4) RETURNING `+businessColumns,
+		name, userID, defCurrency, schedule,
+	))
+	if err != nil {
+		logger.Error("insert business failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO business_user (business_id, user_id, role) VALUES ($1, $2, $3)`,
+		b.ID, userID, businessuser.RoleOwner,
+	); err != nil {
+		logger.Error("insert owner membership failed", slog.String("business_id", b.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	if err := replaceCurrencies(ctx, tx, b.ID, currencies); err != nil {
+		logger.Error("insert currencies failed", slog.String("business_id", b.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	b.Currencies = currencies
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("commit failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusCreated, b)
+}
+
+// validateName trims the business name and checks it is present and reasonably sized.
+func validateName(name *string) (string, error) {
+	if name == nil {
+		return "", errors.New("name is required")
+	}
+	n := strings.TrimSpace(*name)
+	if n == "" {
+		return "", errors.New("name is required")
+	}
+	if len(n) > 200 {
+		return "", errors.New("name must be at most 200 characters")
+	}
+	return n, nil
+}
+
+// validatePayoutSchedule returns the requested schedule, or cur when none was given.
+func validatePayoutSchedule(s *string, cur PayoutSchedule) (PayoutSchedule, error) {
+	if s == nil {
+		return cur, nil
+	}
+	switch v := PayoutSchedule(strings.TrimSpace(*s)); v {
+	case PayoutDaily, PayoutWeekly, PayoutManual:
+		return v, nil
+	default:
+		return "", errors.New("payout_schedule must be one of daily, weekly, manual")
+	}
+}
@@ -0,0 +1,103 @@
+package business
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"backend/internal/httpx"
+	"backend/internal/money"
+)
+
+// defaultCurrency is used for new businesses that do not choose one.
+const defaultCurrency = "BZD"
+
+// settleCurrencies applies the optional payload fields on top of the current settings
+// and validates the result against the ISO 4217 registry.
+// When no currency list is given for a business without one, the list is just the default.
+func settleCurrencies(p BusinessPayload, curDefault string, curList []string) (string, []string, error) {
+	def := curDefault
+	if p.DefaultCurrency != nil {
+		def = strings.ToUpper(strings.TrimSpace(*p.DefaultCurrency))
+	}
+	if _, ok := money.LookupCurrency(def); !ok {
+		return "", nil, fmt.Errorf("default_currency %q is not a supported ISO 4217 currency", def)
+	}
+
+	list := curList
+	if p.Currencies != nil {
+		list = make([]string, 0, len(p.Currencies))
+		for _, c := range p.Currencies {
+			c = strings.ToUpper(strings.TrimSpace(c))
+			if _, ok := money.LookupCurrency(c); !ok {
+				return "", nil, fmt.Errorf("currency %q is not a supported ISO 4217 currency", c)
+			}
+			if !slices.Contains(list, c) {
+				list = append(list, c)
+			}
+		}
+	}
+	if len(list) == 0 {
+		list = []string{def}
+	}
+	if !slices.Contains(list, def) {
+		return "", nil, errors.New("default_currency must be one of currencies")
+	}
+	slices.Sort(list)
+	return def, list, nil
+}
+
+// replaceCurrencies overwrites the enabled currencies of a business.
+func replaceCurrencies(ctx context.Context, tx *sql.Tx, businessID string, currencies []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM business_currency WHERE business_id = $1`, businessID); err != nil {
+		return err
+	}
+	for _, c := range currencies {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO business_currency (business_id, currency) VALUES ($1, $2)`, businessID, c,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveCurrency picks the order currency for a business: the requested code when the business has
+// enabled it, or the business default when requested is empty. It writes 400 for disabled currencies.
+// Returns the currency and true if the request may proceed; false otherwise (an error response has been written).
+func ResolveCurrency(ctx context.Context, db *sql.DB, w http.ResponseWriter, businessID string, requested string) (string, bool) {
+	var def string
+	var enabled bool
+	err := db.QueryRowContext(ctx, `
+		SELECT b.default_currency,
+		       EXISTS (SELECT 1 FROM business_currency bc WHERE bc.business_id = b.id AND bc.currency = $2)
+		FROM business b
+		WHERE b.id = $1::uuid`,
+		businessID, requested,
+	).Scan(&def, &enabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusNotFound, "business not found")
+		return "", false
+	} else if err != nil {
+		slog.Default().With(
+			slog.String("component", "business"),
+			slog.String("op", "ResolveCurrency"),
+			slog.String("business_id", businessID),
+		).Error("currency lookup failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return "", false
+	}
+	if requested == "" {
+		return def, true
+	}
+	if !enabled {
+		httpx.WriteErr(w, http.StatusBadRequest, fmt.Sprintf("currency %s is not enabled for this business", requested))
+		return "", false
+	}
+	return requested, true
+}
@@ -0,0 +1,92 @@
+package business
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+)
+
+// attachGetRoutes registers the read (GET) endpoint.
+func attachGetRoutes(r chi.Router, db *sql.DB) {
+	r.Get("/{businessID}", func(w http.ResponseWriter, r *http.Request) { getBusiness(db, w, r) })
+}
+
+// getBusiness handles GET /api/businesses/{businessID}
+//
+// @Summary      Get a business
+// @Description  Returns a business the authenticated user belongs to, including archived ones. Not available to API keys.
+// @Tags         businesses
+// @Produce      json
+// @Param        businessID  path      string  true  "Business ID"
+// @Success      200         {object}  Business
+// @Failure      403         {object}  ErrorResponse
+// @Failure      404         {object}  ErrorResponse
+// @Router       /api/businesses/{businessID} [get]
+func getBusiness(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	businessID, ok := businessIDParam(w, r)
+	if !ok {
+		return
+	}
+	if !businessuser.AssertUserBelongsToBusiness(ctx, db, w, businessID, u) {
+		return
+	}
+
+	b, ok := loadBusiness(ctx, db, w, businessID)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(b)
+}
+
+// businessIDParam returns the {businessID} URL parameter, writing 404 when it is not a UUID.
+func businessIDParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	businessID := chi.URLParam(r, "businessID")
+	if _, err := uuid.Parse(businessID); err != nil {
+		httpx.WriteErr(w, http.StatusNotFound, "business not found")
+		return "", false
+	}
+	return businessID, true
+}
+
+// loadBusiness fetches a business and its currencies by ID, writing 404 or 500 on failure.
+func loadBusiness(ctx context.Context, db *sql.DB, w http.ResponseWriter, businessID string) (Business, bool) {
+	b, err := scanBusiness(db.QueryRowContext(ctx,
+		`SELECT `+businessColumns+` FROM business WHERE id = $1::uuid`, businessID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusNotFound, "business not found")
+		return Business{}, false
+	} else if err != nil {
+		slog.Error("query business failed", slog.String("business_id", businessID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Business{}, false
+	}
+	if b.Currencies, err = loadCurrencies(ctx, db, businessID); err != nil {
+		slog.Error("query business currencies failed", slog.String("business_id", businessID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Business{}, false
+	}
+	return b, true
+}
@@ -0,0 +1,18 @@
+package business
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Routes aggregates all business submodule routes (create, get, update, archive)
+func Routes(db *sql.DB) http.Handler {
+	r := chi.NewRouter()
+	attachCreateRoutes(r, db)
+	attachGetRoutes(r, db)
+	attachUpdateRoutes(r, db)
+	attachArchiveRoutes(r, db)
+	return r
+}
@@ -0,0 +1,68 @@
+package business
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type Business struct {
+	ID              string         `json:"id"`
+	Name            string         `json:"name"`
+	DefaultCurrency string         `json:"default_currency"`
+	Currencies      []string       `json:"currencies"`
+	PayoutSchedule  PayoutSchedule `json:"payout_schedule"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	ArchivedAt      *time.Time     `json:"archived_at,omitempty"`
+}
+
+// PayoutSchedule is how often available funds are paid out to the business automatically.
+type PayoutSchedule string
+
+const (
+	PayoutDaily  PayoutSchedule = "daily"
+	PayoutWeekly PayoutSchedule = "weekly" // on Mondays
+	PayoutManual PayoutSchedule = "manual" // only when requested through the API
+)
+
+// businessColumns is the column list scanned by scanBusiness.
+const businessColumns = `id, name, default_currency, payout_schedule, created_at, updated_at, archived_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// scanBusiness scans businessColumns. Currencies are loaded separately with loadCurrencies.
+func scanBusiness(row rowScanner) (Business, error) {
+	var b Business
+	if err := row.Scan(&b.ID, &b.Name, &b.DefaultCurrency, &b.PayoutSchedule, &b.CreatedAt, &b.UpdatedAt, &b.ArchivedAt); err != nil {
+		return Business{}, err
+	}
+	return b, nil
+}
+
+// loadCurrencies returns the currencies enabled for a business, sorted by code.
+func loadCurrencies(ctx context.Context, q queryer, businessID string) ([]string, error) {
+	rows, err := q.QueryContext(ctx,
+		`SELECT currency FROM business_currency WHERE business_id = $1::uuid ORDER BY currency`, businessID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	currencies := make([]string, 0)
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, c)
+	}
+	return currencies, rows.Err()
+}
@@ -0,0 +1,148 @@
+package business
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+)
+
+// attachUpdateRoutes registers the update (PATCH) endpoint.
+func attachUpdateRoutes(r chi.Router, db *sql.DB) {
+	r.Patch("/{businessID}", func(w http.ResponseWriter, r *http.Request) { updateBusiness(db, w, r) })
+}
+
+// updateBusiness handles PATCH /api/businesses/{businessID}
+//
+// @Summary      Update a business
+// @Description  Renames a business or changes its currencies or payout schedule. Omitted fields are left unchanged. Archived businesses cannot be updated.
+// @Tags         businesses
+// @Accept       json
+// @Produce      json
+// @Param        businessID  path      string           true  "Business ID"
+// @Param        payload     body      BusinessPayload  true  "Fields to update"
+// @Success      200         {object}  Business
+// @Failure      400         {object}  ErrorResponse
+// @Failure      403         {object}  businessuser.MissingPermissionResponse
+// @Failure      404         {object}  ErrorResponse
+// @Failure      409         {object}  ErrorResponse
+// @Router       /api/businesses/{businessID} [patch]
+func updateBusiness(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p BusinessPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		httpx.WriteBadRequest(w)
+		return
+	}
+
+	businessID, ok := businessIDParam(w, r)
+	if !ok {
+		return
+	}
+	logger := slog.Default().With(
+		slog.String("component", "businesses"),
+		slog.String("op", "updateBusiness"),
+		slog.String("business_id", businessID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if !businessuser.AssertPermission(ctx, db, w, businessID, u, businessuser.PermBusinessManage) {
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("begin tx failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	cur, err := scanBusiness(tx.QueryRowContext(ctx,
+		`SELECT `+businessColumns+` FROM business WHERE id = $1::uuid FOR UPDATE`, businessID,
+	))
+	if err != nil {
+		logger.Error("query business failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	if cur.ArchivedAt != nil {
+		httpx.WriteErr(w, http.StatusConflict, "business is archived")
+		return
+	}
+	curCurrencies, err := loadCurrencies(ctx, tx, businessID)
+	if err != nil {
+		logger.Error("query business currencies failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	name := cur.Name
+	if p.Name != nil {
+		if name, err = validateName(p.Name); err != nil {
+			httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	defCurrency, currencies, err := settleCurrencies(p, cur.DefaultCurrency, curCurrencies)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	schedule, err := validatePayoutSchedule(p.PayoutSchedule, cur.PayoutSchedule)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	b, err := scanBusiness(tx.QueryRowContext(ctx, `
+		UPDATE business
+		SET name = $2, default_currency = $3, payout_schedule = $4, updated_at = now()
+		WHERE id = $1::uuid
+		RETURNING `+businessColumns,
+		businessID, name, defCurrency, schedule,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusNotFound, "business not found")
+		return
+	} else if err != nil {
+		logger.Error("update business failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	if p.Currencies != nil {
+		if err := replaceCurrencies(ctx, tx, businessID, currencies); err != nil {
+			logger.Error("replace currencies failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+	}
+	b.Currencies = currencies
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("commit failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(b)
+}
@@ -5,36 +5,99 @@ import (
 	"database/sql"
 	"log/slog"
 	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
 
 	"backend/internal/auth"
 	httpx "backend/internal/httpx"
 )
 
+// MissingPermissionResponse is the 403 body written when a member lacks a permission.
+type MissingPermissionResponse struct {
+	Error      string     `json:"error"`
+	Permission Permission `json:"permission"`
+}
+
+// LookupRole returns the user's role in the given business.
+// ok is false when the user is not a member; err is only set on internal failure.
+// API keys only count as members of their own business. Nobody is a member of a malformed ID.
+func LookupRole(ctx context.Context, db *sql.DB, businessID string, u *auth.User) (role Role, ok bool, err error) {
+	if _, err := uuid.Parse(businessID); err != nil {
+		return "", false, nil
+	}
+	if u.APIKey != nil && !strings.EqualFold(u.APIKey.BusinessID, businessID) {
+		return "", false, nil
+	}
+	err = db.QueryRowContext(ctx, `
+		SELECT bu.role
+		FROM business_user bu
+		JOIN "user" usr ON usr.id = bu.user_id
+		WHERE bu.business_id = $1::uuid AND usr.firebase_id = $2`,
+		businessID, u.UID,
+	).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return role, true, nil
+}
+
 // AssertUserBelongsToBusiness checks whether the given user belongs to the given business.
+// Membership alone is not covered by any key scope, so API key principals are always refused;
+// endpoints that keys may use check a permission with AssertPermission instead.
 // It logs and writes an HTTP error to the ResponseWriter when the check fails or on internal error.
 // Returns true if membership exists and the request may proceed; false otherwise (an error response has been written).
 func AssertUserBelongsToBusiness(ctx context.Context, db *sql.DB, w http.ResponseWriter, businessID string, u *auth.User) bool {
-	var exists bool
-	if err := db.QueryRowContext(ctx,
-		`SELECT EXISTS (
-			SELECT 1
-			FROM business_user bu
-			JOIN "user" usr ON usr.id = bu.user_id
-			WHERE bu.business_id = $1::uuid AND usr.firebase_id = $2
-		)`, businessID, u.UID,
-	).Scan(&exists); err != nil {
+	if u.APIKey != nil {
+		httpx.WriteErr(w, http.StatusForbidden, "API keys cannot use this endpoint")
+		return false
+	}
+	_, ok := assertMember(ctx, db, w, businessID, u, "AssertUserBelongsToBusiness")
+	return ok
+}
+
+// AssertPermission checks that the user is a member of the business and that their role grants perm
+// (and, for API keys, that a key scope grants it too).
+// Non-members get 403 "forbidden"; members without the permission get 403 naming the missing permission.
+// Returns true if the request may proceed; false otherwise (an error response has been written).
+func AssertPermission(ctx context.Context, db *sql.DB, w http.ResponseWriter, businessID string, u *auth.User, perm Permission) bool {
+	role, ok := assertMember(ctx, db, w, businessID, u, "AssertPermission")
+	if !ok {
+		return false
+	}
+	if !Allows(u, role, perm) {
+		WriteMissingPermission(w, perm)
+		return false
+	}
+	return true
+}
+
+// WriteMissingPermission responds with HTTP 403 naming the permission the caller lacks.
+func WriteMissingPermission(w http.ResponseWriter, perm Permission) {
+	httpx.WriteJSON(w, http.StatusForbidden, MissingPermissionResponse{
+		Error:      "missing permission: " + string(perm),
+		Permission: perm,
+	})
+}
+
+func assertMember(ctx context.Context, db *sql.DB, w http.ResponseWriter, businessID string, u *auth.User, op string) (Role, bool) {
+	role, ok, err := LookupRole(ctx, db, businessID, u)
+	if err != nil {
 		slog.Default().With(
 			slog.String("component", "businessuser"),
-			slog.String("op", "AssertUserBelongsToBusiness"),
+			slog.String("op", op),
 			slog.String("business_id", businessID),
 			slog.String("firebase_id", u.UID),
 		).Error("membership check failed", slog.Any("err", err))
 		httpx.WriteInternalServerError(w)
-		return false
+		return "", false
 	}
-	if !exists {
+	if !ok {
 		httpx.WriteForbidden(w)
-		return false
+		return "", false
 	}
-	return true
+	return role, true
 }
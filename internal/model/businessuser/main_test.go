@@ -0,0 +1,55 @@
+package businessuser
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/auth"
+)
+
+func TestAssertUserBelongsToBusinessRefusesAPIKeys(t *testing.T) {
+	const businessID = "6f1c2d3e-4a5b-4c6d-8e7f-901234567890"
+	u := &auth.User{UID: "creator", APIKey: &auth.APIKey{ID: "key-1", BusinessID: businessID, Scopes: []string{string(ScopeOrdersRead)}}}
+
+	// The key is refused before the database is consulted.
+	rec := httptest.NewRecorder()
+	if AssertUserBelongsToBusiness(context.Background(), nil, rec, businessID, u) {
+		t.Fatal("API key passed the membership check")
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", rec.Code)
+	}
+}
+
+func TestAllows(t *testing.T) {
+	key := func(scopes ...Scope) *auth.APIKey {
+		k := &auth.APIKey{ID: "key-1"}
+		for _, s := range scopes {
+			k.Scopes = append(k.Scopes, string(s))
+		}
+		return k
+	}
+	tests := []struct {
+		name string
+		key  *auth.APIKey
+		role Role
+		perm Permission
+		want bool
+	}{
+		{"member with permission", nil, RoleCashier, PermOrdersCreate, true},
+		{"member without permission", nil, RoleCashier, PermOrdersReadAll, false},
+		{"key with scope", key(ScopeOrdersRead), RoleOwner, PermOrdersReadAll, true},
+		{"key without scope", key(ScopeOrdersRead), RoleOwner, PermOrdersCreate, false},
+		{"key without scopes", key(), RoleOwner, PermOrdersRead, false},
+		{"key scope beyond the creator's role", key(ScopeOrdersRead), RoleCashier, PermOrdersReadAll, false},
+		{"member management is never granted to keys", key(Scopes...), RoleOwner, PermMembersManage, false},
+	}
+	for _, tt := range tests {
+		u := &auth.User{UID: "creator", APIKey: tt.key}
+		if got := Allows(u, tt.role, tt.perm); got != tt.want {
+			t.Errorf("%s: Allows(%s, %s) = %v, want %v", tt.name, tt.role, tt.perm, got, tt.want)
+		}
+	}
+}
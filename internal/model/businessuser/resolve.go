@@ -0,0 +1,92 @@
+package businessuser
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"backend/internal/auth"
+	httpx "backend/internal/httpx"
+)
+
+// BusinessCandidate is one of the businesses a user could have meant.
+type BusinessCandidate struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// AmbiguousBusinessResponse is the 400 body written when business_id is omitted
+// and the user belongs to more than one business.
+type AmbiguousBusinessResponse struct {
+	Error      string              `json:"error"`
+	Candidates []BusinessCandidate `json:"candidates"`
+}
+
+// ResolveBusinessID returns the business a request targets. A non-empty requested ID is
+// validated and returned as-is (membership is still checked by the caller). When requested is
+// empty and the user belongs to exactly one active business, that business is used; zero or
+// several businesses yield 400, the latter listing the candidates. API keys default to their business.
+// Returns false after writing an error response.
+func ResolveBusinessID(ctx context.Context, db *sql.DB, w http.ResponseWriter, requested string, u *auth.User) (string, bool) {
+	requested = strings.TrimSpace(requested)
+	if requested != "" {
+		if _, err := uuid.Parse(requested); err != nil {
+			httpx.WriteErr(w, http.StatusBadRequest, "business_id must be a UUID")
+			return "", false
+		}
+		return requested, true
+	}
+	if u.APIKey != nil {
+		return u.APIKey.BusinessID, true
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT b.id, b.name
+		FROM business_user bu
+		JOIN "user" usr ON usr.id = bu.user_id
+		JOIN business b ON b.id = bu.business_id
+		WHERE usr.firebase_id = $1 AND b.archived_at IS NULL
+		ORDER BY b.created_at DESC`,
+		u.UID,
+	)
+	if err != nil {
+		slog.Error("query candidate businesses failed", slog.String("firebase_id", u.UID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return "", false
+	}
+	defer rows.Close()
+
+	candidates := make([]BusinessCandidate, 0)
+	for rows.Next() {
+		var c BusinessCandidate
+		if err := rows.Scan(&c.ID, &c.Name); err != nil {
+			slog.Error("scan candidate business failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return "", false
+		}
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error("rows error after iteration", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return "", false
+	}
+
+	switch len(candidates) {
+	case 0:
+		httpx.WriteErr(w, http.StatusBadRequest, "business_id is required: user does not belong to any business")
+		return "", false
+	case 1:
+		return candidates[0].ID, true
+	default:
+		httpx.WriteJSON(w, http.StatusBadRequest, AmbiguousBusinessResponse{
+			Error:      "business_id is required: user belongs to more than one business",
+			Candidates: candidates,
+		})
+		return "", false
+	}
+}
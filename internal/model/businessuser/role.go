@@ -0,0 +1,67 @@
+package businessuser
+
+// Role is a user's role within a business, stored in business_user.role.
+type Role string
+
+const (
+	RoleOwner   Role = "owner"
+	RoleAdmin   Role = "admin"
+	RoleCashier Role = "cashier"
+	RoleViewer  Role = "viewer"
+)
+
+// Permission names an action that handlers check before proceeding.
+type Permission string
+
+const (
+	PermOrdersRead      Permission = "orders:read"
+	PermOrdersReadAll   Permission = "orders:read_all" // see orders created by other members
+	PermOrdersCreate    Permission = "orders:create"
+	PermOrdersUpdate    Permission = "orders:update"
+	PermOrdersRefund    Permission = "orders:refund"
+	PermFinanceRead     Permission = "finance:read" // ledger balances and payouts
+	PermCustomersRead   Permission = "customers:read"
+	PermCustomersManage Permission = "customers:manage"
+	PermMembersManage   Permission = "members:manage"
+	PermBusinessManage  Permission = "business:manage"
+	PermBusinessArchive Permission = "business:archive"
+)
+
+// rolePermissions is the permission matrix. Owners can do everything.
+var rolePermissions = map[Role][]Permission{
+	RoleOwner: {
+		PermOrdersRead, PermOrdersReadAll, PermOrdersCreate, PermOrdersUpdate, PermOrdersRefund,
+		PermFinanceRead, PermCustomersRead, PermCustomersManage,
+		PermMembersManage, PermBusinessManage, PermBusinessArchive,
+	},
+	RoleAdmin: {
+		PermOrdersRead, PermOrdersReadAll, PermOrdersCreate, PermOrdersUpdate, PermOrdersRefund,
+		PermFinanceRead, PermCustomersRead, PermCustomersManage,
+		PermMembersManage, PermBusinessManage,
+	},
+	RoleCashier: {
+		PermOrdersRead, PermOrdersCreate, PermOrdersUpdate,
+		PermCustomersRead, PermCustomersManage,
+	},
+	RoleViewer: {
+		PermOrdersRead, PermOrdersReadAll,
+		PermCustomersRead,
+	},
+}
+
+// ParseRole validates a role name.
+func ParseRole(s string) (Role, bool) {
+	r := Role(s)
+	_, ok := rolePermissions[r]
+	return r, ok
+}
+
+// Can reports whether the role grants the permission.
+func (r Role) Can(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package businessuser
+
+import "backend/internal/auth"
+
+// Scope is a group of permissions that can be granted to an API key. Member, business and
+// API key management are deliberately not available to keys.
+type Scope string
+
+const (
+	ScopeOrdersRead     Scope = "orders:read"
+	ScopeOrdersWrite    Scope = "orders:write"
+	ScopeRefundsWrite   Scope = "refunds:write"
+	ScopeCustomersRead  Scope = "customers:read"
+	ScopeCustomersWrite Scope = "customers:write"
+	ScopeFinanceRead    Scope = "finance:read"
+)
+
+// Scopes lists every scope in the order they are documented.
+var Scopes = []Scope{ScopeOrdersRead, ScopeOrdersWrite, ScopeRefundsWrite, ScopeCustomersRead, ScopeCustomersWrite, ScopeFinanceRead}
+
+// scopePermissions maps each scope to the permissions it grants.
+var scopePermissions = map[Scope][]Permission{
+	ScopeOrdersRead:     {PermOrdersRead, PermOrdersReadAll},
+	ScopeOrdersWrite:    {PermOrdersCreate, PermOrdersUpdate},
+	ScopeRefundsWrite:   {PermOrdersRefund},
+	ScopeCustomersRead:  {PermCustomersRead},
+	ScopeCustomersWrite: {PermCustomersManage},
+	ScopeFinanceRead:    {PermFinanceRead},
+}
+
+// ParseScope validates a scope name.
+func ParseScope(s string) (Scope, bool) {
+	sc := Scope(s)
+	_, ok := scopePermissions[sc]
+	return sc, ok
+}
+
+// Allows reports whether a member with role may use perm. Requests made with an API key
+// additionally need a key scope granting perm.
+func Allows(u *auth.User, role Role, perm Permission) bool {
+	if !role.Can(perm) {
+		return false
+	}
+	if u.APIKey == nil {
+		return true
+	}
+	for _, s := range u.APIKey.Scopes {
+		for _, granted := range scopePermissions[Scope(s)] {
+			if granted == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
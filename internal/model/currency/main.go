@@ -0,0 +1,30 @@
+package currency
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/money"
+)
+
+// Routes exposes the built-in ISO 4217 registry. It has no state, so it needs no database.
+func Routes() http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", listCurrencies)
+	return r
+}
+
+// listCurrencies handles GET /api/currencies
+//
+// @Summary      List supported currencies
+// @Description  Returns the ISO 4217 currencies the API accepts, with their minor-unit exponent and symbol
+// @Tags         currencies
+// @Produce      json
+// @Success      200  {array}  money.Currency
+// @Router       /api/currencies [get]
+func listCurrencies(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(money.Currencies())
+}
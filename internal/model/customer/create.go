@@ -0,0 +1,102 @@
+package customer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/business"
+	"backend/internal/model/businessuser"
+)
+
+// attachCreateRoutes registers the create (POST) endpoint.
+func attachCreateRoutes(r chi.Router, db *sql.DB) {
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) { createCustomer(db, w, r) })
+}
+
+// createCustomer handles POST /api/customers
+//
+// @Summary      Create a customer
+// @Description  Adds a customer to a business. At least one of name, email and phone is required; emails are unique per business.
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      CustomerPayload  true  "Customer payload"
+// @Success      201      {object}  Customer
+// @Failure      400      {object}  ErrorResponse
+// @Failure      403      {object}  businessuser.MissingPermissionResponse
+// @Failure      409      {object}  ErrorResponse  "Email already used by another customer, or business is archived"
+// @Router       /api/customers [post]
+func createCustomer(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p CustomerPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		httpx.WriteBadRequest(w)
+		return
+	}
+	p.BusinessID = strings.TrimSpace(p.BusinessID)
+	if p.BusinessID == "" {
+		httpx.WriteErr(w, http.StatusBadRequest, "business_id is required")
+		return
+	}
+	var c Customer
+	if err := p.apply(&c); err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger := slog.Default().With(
+		slog.String("component", "customers"),
+		slog.String("op", "createCustomer"),
+		slog.String("business_id", p.BusinessID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if !businessuser.AssertPermission(ctx, db, w, p.BusinessID, u, businessuser.PermCustomersManage) {
+		return
+	}
+	if !business.AssertActive(ctx, db, w, p.BusinessID) {
+		return
+	}
+
+	c, err := scanCustomer(db.QueryRowContext(ctx, `
+		INSERT INTO customer (business_id, name, email, phone)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+customerColumns,
+		p.BusinessID, c.Name, c.Email, c.Phone,
+	))
+	if isDuplicateEmail(err) {
+		httpx.WriteErr(w, http.StatusConflict, "another customer of this business already has that email")
+		return
+	} else if err != nil {
+		logger.Error("insert customer failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusCreated, c)
+}
+
+// isDuplicateEmail reports whether err violates the per-business email uniqueness.
+func isDuplicateEmail(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "customer_business_email_key"
+}
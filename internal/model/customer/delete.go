@@ -0,0 +1,63 @@
+package customer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+)
+
+// attachDeleteRoutes registers the delete endpoint.
+func attachDeleteRoutes(r chi.Router, db *sql.DB) {
+	r.Delete("/{customerID}", func(w http.ResponseWriter, r *http.Request) { deleteCustomer(db, w, r) })
+}
+
+// deleteCustomer handles DELETE /api/customers/{customerID}
+//
+// @Summary      Delete a customer
+// @Description  Deletes a customer that has no orders. Customers with orders are kept so their history stays intact.
+// @Tags         customers
+// @Param        customerID  path  string  true  "Customer ID"
+// @Success      204
+// @Failure      403  {object}  businessuser.MissingPermissionResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse  "Customer has orders"
+// @Router       /api/customers/{customerID} [delete]
+func deleteCustomer(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	customerID := chi.URLParam(r, "customerID")
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	c, ok := loadCustomer(ctx, db, w, customerID, u, businessuser.PermCustomersManage)
+	if !ok {
+		return
+	}
+
+	_, err := db.ExecContext(ctx, `DELETE FROM customer WHERE id = $1`, c.ID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		httpx.WriteErr(w, http.StatusConflict, "customer has orders and cannot be deleted")
+		return
+	} else if err != nil {
+		slog.Error("delete customer failed", slog.String("customer_id", c.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
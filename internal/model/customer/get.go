@@ -0,0 +1,180 @@
+package customer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+	"backend/internal/pagination"
+)
+
+// ListCustomersResponse is one page of customers. NextCursor is null on the last page.
+type ListCustomersResponse struct {
+	Customers  []Customer `json:"customers"`
+	NextCursor *string    `json:"next_cursor"`
+}
+
+// attachGetRoutes registers the list and single-customer (GET) endpoints.
+func attachGetRoutes(r chi.Router, db *sql.DB) {
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) { listCustomers(db, w, r) })
+	r.Get("/{customerID}", func(w http.ResponseWriter, r *http.Request) { getCustomer(db, w, r) })
+}
+
+// listCustomers handles GET /api/customers?business_id=...
+//
+// @Summary      List customers
+// @Description  Returns a page of the business's customers, newest first. q matches name, email or phone case-insensitively. If business_id is omitted and the authenticated user belongs to exactly one business, that business is used. Pass next_cursor back as cursor to fetch the following page.
+// @Tags         customers
+// @Produce      json
+// @Param        business_id  query     string  false  "Business ID"
+// @Param        q            query     string  false  "Search text"
+// @Param        limit        query     int     false  "Page size (1-200, default 50)"
+// @Param        cursor       query     string  false  "next_cursor from the previous page"
+// @Success      200          {object}  ListCustomersResponse
+// @Failure      400          {object}  businessuser.AmbiguousBusinessResponse
+// @Failure      403          {object}  businessuser.MissingPermissionResponse
+// @Router       /api/customers [get]
+func listCustomers(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	limit, after, err := pagination.Parse(q, false)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var afterAt *time.Time
+	var afterID *string
+	if after != nil {
+		afterAt, afterID = &after.CreatedAt, &after.ID
+	}
+	search := strings.TrimSpace(q.Get("q"))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	bizID, ok := businessuser.ResolveBusinessID(ctx, db, w, q.Get("business_id"), u)
+	if !ok {
+		return
+	}
+	if !businessuser.AssertPermission(ctx, db, w, bizID, u, businessuser.PermCustomersRead) {
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+customerColumns+`
+		FROM customer
+		WHERE business_id = $1::uuid
+			AND ($2 = '' OR name ILIKE '%' || $2 || '%' OR email ILIKE '%' || $2 || '%' OR phone ILIKE '%' || $2 || '%')
+			AND ($3::timestamptz IS NULL OR (created_at, id) < ($3, $4::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $5`,
+		bizID, escapeLike(search), afterAt, afterID, limit+1,
+	)
+	if err != nil {
+		slog.Error("query customers failed", slog.String("business_id", bizID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+
+	customers := make([]Customer, 0)
+	for rows.Next() {
+		c, err := scanCustomer(rows)
+		if err != nil {
+			slog.Error("scan customer row failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		customers = append(customers, c)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error("rows error after iteration", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	resp := ListCustomersResponse{Customers: customers}
+	if len(customers) > limit {
+		last := customers[limit-1]
+		next := pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+		resp.Customers = customers[:limit]
+		resp.NextCursor = &next
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// getCustomer handles GET /api/customers/{customerID}
+//
+// @Summary      Get a customer
+// @Tags         customers
+// @Produce      json
+// @Param        customerID  path      string  true  "Customer ID"
+// @Success      200         {object}  Customer
+// @Failure      403         {object}  businessuser.MissingPermissionResponse
+// @Failure      404         {object}  ErrorResponse
+// @Router       /api/customers/{customerID} [get]
+func getCustomer(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	c, ok := loadCustomer(ctx, db, w, chi.URLParam(r, "customerID"), u, businessuser.PermCustomersRead)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(c)
+}
+
+// loadCustomer fetches a customer and checks the caller holds perm in its business.
+// Returns false after writing 404, 403 or 500.
+func loadCustomer(ctx context.Context, db *sql.DB, w http.ResponseWriter, customerID string, u *auth.User, perm businessuser.Permission) (Customer, bool) {
+	if _, err := uuid.Parse(customerID); err != nil {
+		httpx.WriteErr(w, http.StatusNotFound, "customer not found")
+		return Customer{}, false
+	}
+	c, err := scanCustomer(db.QueryRowContext(ctx,
+		`SELECT `+customerColumns+` FROM customer WHERE id = $1`, customerID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusNotFound, "customer not found")
+		return Customer{}, false
+	} else if err != nil {
+		slog.Error("query customer failed", slog.String("customer_id", customerID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Customer{}, false
+	}
+	if !businessuser.AssertPermission(ctx, db, w, c.BusinessID, u, perm) {
+		return Customer{}, false
+	}
+	return c, true
+}
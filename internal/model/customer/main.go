@@ -0,0 +1,20 @@
+package customer
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Routes aggregates all customer submodule routes (create, get, update, delete).
+// orders serves GET /{customerID}/orders; it lives with the orders, which reference customers.
+func Routes(db *sql.DB, orders http.HandlerFunc) http.Handler {
+	r := chi.NewRouter()
+	attachCreateRoutes(r, db)
+	attachGetRoutes(r, db)
+	attachUpdateRoutes(r, db)
+	attachDeleteRoutes(r, db)
+	r.Get("/{customerID}/orders", orders)
+	return r
+}
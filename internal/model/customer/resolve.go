@@ -0,0 +1,43 @@
+package customer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound is returned by Check when the customer does not belong to the business.
+var ErrNotFound = errors.New("customer not found")
+
+// Check verifies inside tx that customerID is a customer of the business.
+func Check(ctx context.Context, tx *sql.Tx, businessID, customerID string) error {
+	if _, err := uuid.Parse(customerID); err != nil {
+		return ErrNotFound
+	}
+	var ok bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM customer WHERE id = $1 AND business_id = $2)`, customerID, businessID,
+	).Scan(&ok); err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// FindOrCreateByEmail returns the ID of the business's customer with email, creating the
+// customer inside tx when there is none. email must be normalized with NormalizeEmail.
+func FindOrCreateByEmail(ctx context.Context, tx *sql.Tx, businessID, email string) (string, error) {
+	var id string
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO customer (business_id, email) VALUES ($1, $2)
+		ON CONFLICT (business_id, lower(email)) WHERE email IS NOT NULL
+		DO UPDATE SET email = customer.email
+		RETURNING id`,
+		businessID, email,
+	).Scan(&id)
+	return id, err
+}
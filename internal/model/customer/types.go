@@ -0,0 +1,105 @@
+package customer
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Customer is someone a business sells to. At least one of name, email and phone is set;
+// emails are unique per business.
+type Customer struct {
+	ID         string    `json:"id"`
+	BusinessID string    `json:"business_id"`
+	Name       *string   `json:"name,omitempty"`
+	Email      *string   `json:"email,omitempty"`
+	Phone      *string   `json:"phone,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CustomerPayload is the body accepted by the create and update endpoints.
+// On update, omitted fields keep their value and an empty string clears one.
+// BusinessID is only read on create.
+type CustomerPayload struct {
+	BusinessID string  `json:"business_id"`
+	Name       *string `json:"name" example:"Ana Pérez"`
+	Email      *string `json:"email" example:"ana@example.com"`
+	Phone      *string `json:"phone" example:"+501 610 0000"`
+}
+
+// customerColumns is the column list scanned by scanCustomer.
+const customerColumns = `id, business_id, name, email, phone, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCustomer(row rowScanner) (Customer, error) {
+	var c Customer
+	err := row.Scan(&c.ID, &c.BusinessID, &c.Name, &c.Email, &c.Phone, &c.CreatedAt, &c.UpdatedAt)
+	return c, err
+}
+
+// NormalizeEmail trims and lowercases an email address and checks it is valid.
+func NormalizeEmail(s string) (string, error) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(s))
+	if err != nil {
+		return "", errors.New("email must be a valid address")
+	}
+	return strings.ToLower(addr.Address), nil
+}
+
+// apply merges p into c, validating every field that is set.
+func (p CustomerPayload) apply(c *Customer) error {
+	if p.Name != nil {
+		n := strings.TrimSpace(*p.Name)
+		if len(n) > 200 {
+			return errors.New("name must be at most 200 characters")
+		}
+		c.Name = nilIfEmpty(n)
+	}
+	if p.Email != nil {
+		c.Email = nil
+		if v := strings.TrimSpace(*p.Email); v != "" {
+			e, err := NormalizeEmail(v)
+			if err != nil {
+				return err
+			}
+			c.Email = &e
+		}
+	}
+	if p.Phone != nil {
+		ph := strings.TrimSpace(*p.Phone)
+		if !validPhone(ph) {
+			return errors.New("phone may only contain digits, spaces and + - ( ) and must be at most 32 characters")
+		}
+		c.Phone = nilIfEmpty(ph)
+	}
+	if c.Name == nil && c.Email == nil && c.Phone == nil {
+		return errors.New("at least one of name, email and phone is required")
+	}
+	return nil
+}
+
+func validPhone(s string) bool {
+	if len(s) > 32 {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9', r == ' ', r == '+', r == '-', r == '(', r == ')':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,88 @@
+package customer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+)
+
+// attachUpdateRoutes registers the update (PATCH) endpoint.
+func attachUpdateRoutes(r chi.Router, db *sql.DB) {
+	r.Patch("/{customerID}", func(w http.ResponseWriter, r *http.Request) { updateCustomer(db, w, r) })
+}
+
+// updateCustomer handles PATCH /api/customers/{customerID}
+//
+// @Summary      Update a customer
+// @Description  Changes a customer's name, email or phone. Omitted fields are left unchanged and empty strings clear them; at least one must remain set. Orders show the new details immediately.
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        customerID  path      string           true  "Customer ID"
+// @Param        payload     body      CustomerPayload  true  "Fields to update"
+// @Success      200         {object}  Customer
+// @Failure      400         {object}  ErrorResponse
+// @Failure      403         {object}  businessuser.MissingPermissionResponse
+// @Failure      404         {object}  ErrorResponse
+// @Failure      409         {object}  ErrorResponse  "Email already used by another customer"
+// @Router       /api/customers/{customerID} [patch]
+func updateCustomer(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p CustomerPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		httpx.WriteBadRequest(w)
+		return
+	}
+
+	customerID := chi.URLParam(r, "customerID")
+	logger := slog.Default().With(
+		slog.String("component", "customers"),
+		slog.String("op", "updateCustomer"),
+		slog.String("customer_id", customerID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	c, ok := loadCustomer(ctx, db, w, customerID, u, businessuser.PermCustomersManage)
+	if !ok {
+		return
+	}
+	if err := p.apply(&c); err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c, err := scanCustomer(db.QueryRowContext(ctx, `
+		UPDATE customer SET name = $2, email = $3, phone = $4, updated_at = now()
+		WHERE id = $1
+		RETURNING `+customerColumns,
+		c.ID, c.Name, c.Email, c.Phone,
+	))
+	if isDuplicateEmail(err) {
+		httpx.WriteErr(w, http.StatusConflict, "another customer of this business already has that email")
+		return
+	} else if err != nil {
+		logger.Error("update customer failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(c)
+}
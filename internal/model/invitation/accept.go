@@ -0,0 +1,185 @@
+package invitation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+)
+
+// AcceptPayload is the body accepted by POST /api/invitations/accept.
+type AcceptPayload struct {
+	Token string `json:"token"`
+}
+
+// AcceptResponse describes the membership created by accepting an invitation.
+type AcceptResponse struct {
+	BusinessID string `json:"business_id"`
+	Role       string `json:"role"`
+}
+
+// attachAcceptRoutes registers the accept endpoint.
+func attachAcceptRoutes(r chi.Router, db *sql.DB, s signer) {
+	r.Post("/accept", func(w http.ResponseWriter, r *http.Request) { acceptInvitation(db, s, w, r) })
+}
+
+// acceptInvitation handles POST /api/invitations/accept
+//
+// @Summary      Accept an invitation
+// @Description  Joins the authenticated user to the inviting business with the invited role. The user's email must match the invited address and be verified with the identity provider.
+// @Tags         invitations
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      AcceptPayload  true  "Invitation token"
+// @Success      200      {object}  AcceptResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse  "Invitation was issued to another email, or the email is not verified"
+// @Failure      409      {object}  ErrorResponse  "Already a member"
+// @Failure      410      {object}  ErrorResponse  "Invitation expired, revoked or already used"
+// @Router       /api/invitations/accept [post]
+func acceptInvitation(db *sql.DB, s signer, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p AcceptPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		httpx.WriteBadRequest(w)
+		return
+	}
+
+	claims, err := s.verify(p.Token, time.Now())
+	if errors.Is(err, errExpiredToken) {
+		httpx.WriteErr(w, http.StatusGone, err.Error())
+		return
+	} else if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger := slog.Default().With(
+		slog.String("component", "invitations"),
+		slog.String("op", "acceptInvitation"),
+		slog.String("invitation_id", claims.InvitationID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("begin tx failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var businessID, email, role, status, nonce string
+	var expiresAt time.Time
+	var archived bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT i.business_id, i.email, i.role, i.status, i.nonce, i.expires_at, b.archived_at IS NOT NULL
+		FROM business_invitation i
+		JOIN business b ON b.id = i.business_id
+		WHERE i.id = $1::uuid
+		FOR UPDATE OF i`,
+		claims.InvitationID,
+	).Scan(&businessID, &email, &role, &status, &nonce, &expiresAt, &archived)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusGone, "invitation no longer exists")
+		return
+	} else if err != nil {
+		logger.Error("query invitation failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	switch {
+	case nonce != claims.Nonce:
+		// A newer token was issued by resend; this one is superseded.
+		httpx.WriteErr(w, http.StatusGone, "invitation token was superseded")
+		return
+	case status != statusPending:
+		httpx.WriteErr(w, http.StatusGone, "invitation is "+status)
+		return
+	case !time.Now().Before(expiresAt):
+		httpx.WriteErr(w, http.StatusGone, errExpiredToken.Error())
+		return
+	case archived:
+		httpx.WriteErr(w, http.StatusConflict, "business is archived")
+		return
+	case u.Email == "" || !strings.EqualFold(u.Email, email):
+		httpx.WriteErr(w, http.StatusForbidden, "invitation was issued to another email")
+		return
+	case !u.EmailVerified:
+		// The email becomes the member's contact address for alerts, so it must be proven.
+		httpx.WriteErr(w, http.StatusForbidden, "verify your email address before accepting the invitation")
+		return
+	}
+
+	var userID string
+	err = tx.QueryRowContext(ctx, `SELECT id FROM "user" WHERE firebase_id = $1`, u.UID).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusBadRequest, "user not initialized")
+		return
+	} else if err != nil {
+		logger.Error("query user failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO business_user (business_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (business_id, user_id) DO NOTHING`,
+		businessID, userID, role,
+	)
+	if err != nil {
+		logger.Error("insert membership failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		httpx.WriteErr(w, http.StatusConflict, "already a member of this business")
+		return
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE business_invitation
+		SET status = $2, accepted_by = $3, accepted_at = now(), updated_at = now()
+		WHERE id = $1`,
+		claims.InvitationID, statusAccepted, userID,
+	); err != nil {
+		logger.Error("mark invitation accepted failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	// The invitation proves the address, so it becomes the member's email for business alerts.
+	if _, err := tx.ExecContext(ctx, `UPDATE "user" SET email = $2, updated_at = now() WHERE id = $1 AND email IS DISTINCT FROM $2`, userID, email); err != nil {
+		logger.Error("record user email failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("commit failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(AcceptResponse{BusinessID: businessID, Role: role})
+}
@@ -0,0 +1,152 @@
+package invitation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/business"
+	"backend/internal/model/businessuser"
+)
+
+// InvitationPayload is the body accepted by POST /api/invitations.
+type InvitationPayload struct {
+	BusinessID string `json:"business_id"`
+	Email      string `json:"email"`
+	Role       string `json:"role"`
+}
+
+// attachCreateRoutes registers the create (POST) endpoint.
+func attachCreateRoutes(r chi.Router, db *sql.DB, s signer, publicURL string) {
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) { createInvitation(db, s, publicURL, w, r) })
+}
+
+// createInvitation handles POST /api/invitations
+//
+// @Summary      Invite a user to a business
+// @Description  Creates a pending invitation for an email address, emails the invitee a link to accept it and returns the signed token, which expires. Only owners may invite owners.
+// @Tags         invitations
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      InvitationPayload  true  "Invitation payload"
+// @Success      201      {object}  IssuedInvitation
+// @Failure      400      {object}  ErrorResponse
+// @Failure      403      {object}  businessuser.MissingPermissionResponse
+// @Failure      409      {object}  ErrorResponse  "A pending invitation already exists for this email"
+// @Router       /api/invitations [post]
+func createInvitation(db *sql.DB, s signer, publicURL string, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p InvitationPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		httpx.WriteBadRequest(w)
+		return
+	}
+	role, err := normalizeAndValidate(&p)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger := slog.Default().With(
+		slog.String("component", "invitations"),
+		slog.String("op", "createInvitation"),
+		slog.String("business_id", p.BusinessID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if !businessuser.AssertPermission(ctx, db, w, p.BusinessID, u, businessuser.PermMembersManage) {
+		return
+	}
+	if role == businessuser.RoleOwner {
+		inviterRole, _, err := businessuser.LookupRole(ctx, db, p.BusinessID, u)
+		if err != nil {
+			logger.Error("lookup inviter role failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		if inviterRole != businessuser.RoleOwner {
+			httpx.WriteErr(w, http.StatusForbidden, "only owners can invite owners")
+			return
+		}
+	}
+	if !business.AssertActive(ctx, db, w, p.BusinessID) {
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("begin tx failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	nonce := newNonce()
+	expiresAt := s.expiry(time.Now())
+	inv, err := scanInvitation(tx.QueryRowContext(ctx, `
+		INSERT INTO business_invitation (business_id, email, role, nonce, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, (SELECT id FROM "user" WHERE firebase_id = $5), $6)
+		RETURNING `+invitationColumns,
+		p.BusinessID, p.Email, role, nonce, u.UID, expiresAt,
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			httpx.WriteErr(w, http.StatusConflict, "a pending invitation already exists for this email; resend it instead")
+			return
+		}
+		logger.Error("insert invitation failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	token := s.sign(tokenClaims{InvitationID: inv.ID, Nonce: nonce, ExpiresAt: expiresAt.Unix()})
+	if err := enqueueInvitationEmail(ctx, tx, publicURL, inv, token, u.UID); err != nil {
+		logger.Error("enqueue invitation email failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		logger.Error("commit failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusCreated, IssuedInvitation{Invitation: inv, Token: token})
+}
+
+// normalizeAndValidate trims and lowercases the email and validates the role.
+func normalizeAndValidate(p *InvitationPayload) (businessuser.Role, error) {
+	p.BusinessID = strings.TrimSpace(p.BusinessID)
+	if p.BusinessID == "" {
+		return "", errors.New("business_id is required")
+	}
+	addr, err := mail.ParseAddress(strings.TrimSpace(p.Email))
+	if err != nil {
+		return "", errors.New("email must be a valid address")
+	}
+	p.Email = strings.ToLower(addr.Address)
+	role, ok := businessuser.ParseRole(strings.ToLower(strings.TrimSpace(p.Role)))
+	if !ok {
+		return "", errors.New("role must be one of owner, admin, cashier, viewer")
+	}
+	return role, nil
+}
@@ -0,0 +1,108 @@
+package invitation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+)
+
+// attachGetRoutes registers the list (GET) endpoint.
+func attachGetRoutes(r chi.Router, db *sql.DB) {
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) { listInvitations(db, w, r) })
+}
+
+// listInvitations handles GET /api/invitations?business_id=...
+//
+// @Summary      List pending invitations
+// @Description  Returns the pending invitations of a business, newest first. Expired invitations are included so they can be resent.
+// @Tags         invitations
+// @Produce      json
+// @Param        business_id  query     string  true  "Business ID"
+// @Success      200          {array}   Invitation
+// @Failure      400          {object}  ErrorResponse
+// @Failure      403          {object}  businessuser.MissingPermissionResponse
+// @Router       /api/invitations [get]
+func listInvitations(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	bizID := strings.TrimSpace(r.URL.Query().Get("business_id"))
+	if bizID == "" {
+		httpx.WriteErr(w, http.StatusBadRequest, "business_id is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if !businessuser.AssertPermission(ctx, db, w, bizID, u, businessuser.PermMembersManage) {
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+invitationColumns+`
+		FROM business_invitation
+		WHERE business_id = $1::uuid AND status = $2
+		ORDER BY created_at DESC`,
+		bizID, statusPending,
+	)
+	if err != nil {
+		slog.Error("query invitations failed", slog.String("business_id", bizID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+
+	invitations := make([]Invitation, 0)
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			slog.Error("scan invitation row failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		invitations = append(invitations, inv)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error("rows error after iteration", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(invitations)
+}
+
+// loadInvitationForAdmin fetches an invitation and checks the caller may manage members of its business.
+// Returns false after writing 404, 403 or 500.
+func loadInvitationForAdmin(ctx context.Context, db *sql.DB, w http.ResponseWriter, invitationID string, u *auth.User) (Invitation, bool) {
+	inv, err := scanInvitation(db.QueryRowContext(ctx,
+		`SELECT `+invitationColumns+` FROM business_invitation WHERE id = $1::uuid`, invitationID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusNotFound, "invitation not found")
+		return Invitation{}, false
+	} else if err != nil {
+		slog.Error("query invitation failed", slog.String("invitation_id", invitationID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Invitation{}, false
+	}
+	if !businessuser.AssertPermission(ctx, db, w, inv.BusinessID, u, businessuser.PermMembersManage) {
+		return Invitation{}, false
+	}
+	return inv, true
+}
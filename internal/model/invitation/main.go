@@ -0,0 +1,23 @@
+package invitation
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Routes aggregates all invitation submodule routes (create, list, resend, revoke, accept).
+// signingKey signs invitation tokens; ttl is how long a freshly issued token stays valid.
+// publicURL is the base URL of the accept link emailed to invitees.
+func Routes(db *sql.DB, signingKey []byte, ttl time.Duration, publicURL string) http.Handler {
+	r := chi.NewRouter()
+	s := signer{key: signingKey, ttl: ttl}
+	attachCreateRoutes(r, db, s, publicURL)
+	attachGetRoutes(r, db)
+	attachResendRoutes(r, db, s, publicURL)
+	attachRevokeRoutes(r, db)
+	attachAcceptRoutes(r, db, s)
+	return r
+}
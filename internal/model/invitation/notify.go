@@ -0,0 +1,37 @@
+package invitation
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+
+	"backend/internal/model/notification"
+)
+
+// acceptPath is the page under the public URL where invitees accept; the token is passed as ?token=.
+const acceptPath = "/invitations/accept"
+
+// enqueueInvitationEmail queues the email carrying token to the invitee. inviterUID is the
+// Firebase UID of the member who issued the token.
+func enqueueInvitationEmail(ctx context.Context, tx *sql.Tx, publicURL string, inv Invitation, token, inviterUID string) error {
+	var businessName string
+	var inviterName *string
+	if err := tx.QueryRowContext(ctx, `
+		SELECT b.name, (SELECT NULLIF(concat_ws(' ', u.name, u.last_name), '') FROM "user" u WHERE u.firebase_id = $2)
+		FROM business b
+		WHERE b.id = $1`,
+		inv.BusinessID, inviterUID,
+	).Scan(&businessName, &inviterName); err != nil {
+		return err
+	}
+	data := notification.InvitationData{
+		BusinessName: businessName,
+		Role:         inv.Role,
+		URL:          publicURL + acceptPath + "?token=" + url.QueryEscape(token),
+		ExpiresAt:    inv.ExpiresAt,
+	}
+	if inviterName != nil {
+		data.InviterName = *inviterName
+	}
+	return notification.Enqueue(ctx, tx, inv.BusinessID, inv.Email, notification.TemplateInvitation, data)
+}
@@ -0,0 +1,87 @@
+package invitation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+)
+
+// attachResendRoutes registers the resend endpoint.
+func attachResendRoutes(r chi.Router, db *sql.DB, s signer, publicURL string) {
+	r.Post("/{invitationID}/resend", func(w http.ResponseWriter, r *http.Request) { resendInvitation(db, s, publicURL, w, r) })
+}
+
+// resendInvitation handles POST /api/invitations/{invitationID}/resend
+//
+// @Summary      Resend an invitation
+// @Description  Issues a fresh token for a pending invitation, extends its expiry and emails the new link to the invitee. Previously issued tokens stop working.
+// @Tags         invitations
+// @Produce      json
+// @Param        invitationID  path      string  true  "Invitation ID"
+// @Success      200           {object}  IssuedInvitation
+// @Failure      403           {object}  businessuser.MissingPermissionResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      409           {object}  ErrorResponse  "Invitation is no longer pending"
+// @Router       /api/invitations/{invitationID}/resend [post]
+func resendInvitation(db *sql.DB, s signer, publicURL string, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	inv, ok := loadInvitationForAdmin(ctx, db, w, chi.URLParam(r, "invitationID"), u)
+	if !ok {
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("begin tx failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	nonce := newNonce()
+	expiresAt := s.expiry(time.Now())
+	resent, err := scanInvitation(tx.QueryRowContext(ctx, `
+		UPDATE business_invitation
+		SET nonce = $2, expires_at = $3, updated_at = now()
+		WHERE id = $1 AND status = $4
+		RETURNING `+invitationColumns,
+		inv.ID, nonce, expiresAt, statusPending,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusConflict, "invitation is no longer pending")
+		return
+	} else if err != nil {
+		slog.Error("resend invitation failed", slog.String("invitation_id", inv.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	token := s.sign(tokenClaims{InvitationID: resent.ID, Nonce: nonce, ExpiresAt: expiresAt.Unix()})
+	if err := enqueueInvitationEmail(ctx, tx, publicURL, resent, token, u.UID); err != nil {
+		slog.Error("enqueue invitation email failed", slog.String("invitation_id", inv.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error("commit failed", slog.String("invitation_id", inv.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, IssuedInvitation{Invitation: resent, Token: token})
+}
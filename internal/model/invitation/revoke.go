@@ -0,0 +1,68 @@
+package invitation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+)
+
+// attachRevokeRoutes registers the revoke (DELETE) endpoint.
+func attachRevokeRoutes(r chi.Router, db *sql.DB) {
+	r.Delete("/{invitationID}", func(w http.ResponseWriter, r *http.Request) { revokeInvitation(db, w, r) })
+}
+
+// revokeInvitation handles DELETE /api/invitations/{invitationID}
+//
+// @Summary      Revoke an invitation
+// @Description  Revokes a pending invitation so its token can no longer be accepted
+// @Tags         invitations
+// @Produce      json
+// @Param        invitationID  path      string  true  "Invitation ID"
+// @Success      200           {object}  Invitation
+// @Failure      403           {object}  businessuser.MissingPermissionResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      409           {object}  ErrorResponse  "Invitation is no longer pending"
+// @Router       /api/invitations/{invitationID} [delete]
+func revokeInvitation(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	inv, ok := loadInvitationForAdmin(ctx, db, w, chi.URLParam(r, "invitationID"), u)
+	if !ok {
+		return
+	}
+
+	revoked, err := scanInvitation(db.QueryRowContext(ctx, `
+		UPDATE business_invitation
+		SET status = $2, revoked_at = now(), updated_at = now()
+		WHERE id = $1 AND status = $3
+		RETURNING `+invitationColumns,
+		inv.ID, statusRevoked, statusPending,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusConflict, "invitation is no longer pending")
+		return
+	} else if err != nil {
+		slog.Error("revoke invitation failed", slog.String("invitation_id", inv.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(revoked)
+}
@@ -0,0 +1,77 @@
+package invitation
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	errInvalidToken = errors.New("invalid invitation token")
+	errExpiredToken = errors.New("invitation token expired")
+)
+
+// tokenClaims is the signed payload of an invitation token.
+type tokenClaims struct {
+	InvitationID string `json:"iid"`
+	Nonce        string `json:"nonce"`
+	ExpiresAt    int64  `json:"exp"`
+}
+
+// signer issues and verifies invitation tokens of the form base64url(claims).base64url(hmac-sha256).
+type signer struct {
+	key []byte
+	ttl time.Duration
+}
+
+// newNonce returns a random value stored on the invitation and embedded in its token.
+func newNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (s signer) expiry(now time.Time) time.Time {
+	return now.Add(s.ttl)
+}
+
+func (s signer) sign(c tokenClaims) string {
+	payload, _ := json.Marshal(c)
+	p := base64.RawURLEncoding.EncodeToString(payload)
+	return p + "." + base64.RawURLEncoding.EncodeToString(s.mac(p))
+}
+
+// verify checks the signature and expiry and returns the embedded claims.
+func (s signer) verify(token string, now time.Time) (tokenClaims, error) {
+	p, sig, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return tokenClaims{}, errInvalidToken
+	}
+	gotMAC, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotMAC, s.mac(p)) {
+		return tokenClaims{}, errInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(p)
+	if err != nil {
+		return tokenClaims{}, errInvalidToken
+	}
+	var c tokenClaims
+	if err := json.Unmarshal(payload, &c); err != nil || c.InvitationID == "" || c.Nonce == "" {
+		return tokenClaims{}, errInvalidToken
+	}
+	if now.Unix() >= c.ExpiresAt {
+		return tokenClaims{}, errExpiredToken
+	}
+	return c, nil
+}
+
+func (s signer) mac(payload string) []byte {
+	m := hmac.New(sha256.New, s.key)
+	m.Write([]byte(payload))
+	return m.Sum(nil)
+}
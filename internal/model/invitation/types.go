@@ -0,0 +1,44 @@
+package invitation
+
+import "time"
+
+type Invitation struct {
+	ID         string     `json:"id"`
+	BusinessID string     `json:"business_id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"`
+	InvitedBy  string     `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IssuedInvitation is returned whenever a new token is minted (create and resend).
+// The token is not stored and cannot be retrieved again.
+type IssuedInvitation struct {
+	Invitation
+	Token string `json:"token"`
+}
+
+const (
+	statusPending  = "pending"
+	statusAccepted = "accepted"
+	statusRevoked  = "revoked"
+)
+
+// invitationColumns is the column list scanned by scanInvitation.
+const invitationColumns = `id, business_id, email, role, status, invited_by, expires_at, created_at, updated_at, accepted_at, revoked_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanInvitation(row rowScanner) (Invitation, error) {
+	var inv Invitation
+	err := row.Scan(&inv.ID, &inv.BusinessID, &inv.Email, &inv.Role, &inv.Status, &inv.InvitedBy,
+		&inv.ExpiresAt, &inv.CreatedAt, &inv.UpdatedAt, &inv.AcceptedAt, &inv.RevokedAt)
+	return inv, err
+}
@@ -0,0 +1,107 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+	"backend/internal/money"
+)
+
+// attachBalanceRoutes registers the balances endpoint.
+func attachBalanceRoutes(r chi.Router, db *sql.DB) {
+	r.Get("/balances", func(w http.ResponseWriter, r *http.Request) { getBalances(db, w, r) })
+}
+
+// getBalances handles GET /api/ledger/balances?business_id=...&as_of=...
+//
+// @Summary      Get ledger balances
+// @Description  Returns the balance of every ledger account of the business, including only transactions posted at or before as_of (default: now). Balances are on each account's normal side: revenue grows with credits, pending, available, fees, refunds and payouts with debits. Requires the finance:read permission. If business_id is omitted and the authenticated user belongs to exactly one business, that business is used.
+// @Tags         ledger
+// @Produce      json
+// @Param        business_id  query     string  false  "Business ID"
+// @Param        as_of        query     string  false  "RFC 3339 timestamp"
+// @Success      200          {object}  BalancesResponse
+// @Failure      400          {object}  businessuser.AmbiguousBusinessResponse
+// @Failure      403          {object}  businessuser.MissingPermissionResponse
+// @Router       /api/ledger/balances [get]
+func getBalances(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	asOf := time.Now().UTC()
+	if v := q.Get("as_of"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			httpx.WriteErr(w, http.StatusBadRequest, "as_of must be an RFC 3339 timestamp")
+			return
+		}
+		asOf = t
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	bizID, ok := businessuser.ResolveBusinessID(ctx, db, w, q.Get("business_id"), u)
+	if !ok {
+		return
+	}
+	if !businessuser.AssertPermission(ctx, db, w, bizID, u, businessuser.PermFinanceRead) {
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT a.code, a.currency,
+			COALESCE((
+				SELECT sum(e.amount_minor)
+				FROM ledger_entry e
+				JOIN ledger_transaction t ON t.id = e.transaction_id
+				WHERE e.account_id = a.id AND t.posted_at <= $2
+			), 0)
+		FROM ledger_account a
+		WHERE a.business_id = $1::uuid
+		ORDER BY a.currency, a.code`,
+		bizID, asOf,
+	)
+	if err != nil {
+		slog.Error("query ledger balances failed", slog.String("business_id", bizID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+
+	balances := make([]Balance, 0)
+	for rows.Next() {
+		var b Balance
+		if err := rows.Scan(&b.Account, &b.Currency, &b.BalanceMinor); err != nil {
+			slog.Error("scan ledger balance row failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		if b.Account.creditNormal() {
+			b.BalanceMinor = -b.BalanceMinor
+		}
+		b.Balance = money.FromMinor(b.BalanceMinor, b.Currency).String()
+		balances = append(balances, b)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error("rows error after iteration", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(BalancesResponse{BusinessID: bizID, AsOf: asOf, Balances: balances})
+}
@@ -0,0 +1,15 @@
+package ledger
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Routes aggregates all ledger submodule routes (balances).
+func Routes(db *sql.DB) http.Handler {
+	r := chi.NewRouter()
+	attachBalanceRoutes(r, db)
+	return r
+}
@@ -0,0 +1,62 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrUnbalanced is returned by Post when the entries of a transaction do not sum to zero.
+var ErrUnbalanced = errors.New("ledger: entries do not balance")
+
+// Post appends t to the ledger inside the caller's transaction, so entries are only recorded
+// for changes that commit. Accounts are created on first use. The database rejects a second
+// posting of the same kind for a payment or refund.
+func Post(ctx context.Context, tx *sql.Tx, t Transaction) error {
+	var sum int64
+	for _, e := range t.Entries {
+		if e.AmountMinor == 0 {
+			return fmt.Errorf("ledger: zero amount on %s entry", e.Account)
+		}
+		sum += e.AmountMinor
+	}
+	if len(t.Entries) < 2 || sum != 0 {
+		return ErrUnbalanced
+	}
+
+	var txnID string
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO ledger_transaction (business_id, kind, currency, order_id, payment_id, refund_id, payout_id, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`,
+		t.BusinessID, t.Kind, t.Currency, nullIfEmpty(t.OrderID), nullIfEmpty(t.PaymentID), nullIfEmpty(t.RefundID),
+		nullIfEmpty(t.PayoutID), nullIfEmpty(t.Description),
+	).Scan(&txnID); err != nil {
+		return fmt.Errorf("ledger: insert transaction: %w", err)
+	}
+
+	for _, e := range t.Entries {
+		if _, err := tx.ExecContext(ctx, `
+			WITH account AS (
+				INSERT INTO ledger_account (business_id, code, currency)
+				VALUES ($2, $3, $4)
+				ON CONFLICT (business_id, code, currency) DO UPDATE SET code = EXCLUDED.code
+				RETURNING id
+			)
+			INSERT INTO ledger_entry (transaction_id, account_id, amount_minor)
+			SELECT $1, id, $5 FROM account`,
+			txnID, t.BusinessID, e.Account, t.Currency, e.AmountMinor,
+		); err != nil {
+			return fmt.Errorf("ledger: insert %s entry: %w", e.Account, err)
+		}
+	}
+	return nil
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+package ledger
+
+import "time"
+
+// Account is the code of one of a business's ledger accounts. A business has one account per
+// code and currency, created on first use.
+type Account string
+
+const (
+	AccountPending   Account = "pending"   // captured funds the provider has not settled yet
+	AccountAvailable Account = "available" // settled funds waiting to be paid out
+	AccountFees      Account = "fees"      // processing fees charged by the provider
+	AccountRevenue   Account = "revenue"   // gross amount of captured payments
+	AccountRefunds   Account = "refunds"   // amounts returned to customers
+	AccountPayouts   Account = "payouts"   // funds paid out to the business
+)
+
+// creditNormal reports whether the account's balance grows with credits rather than debits.
+func (a Account) creditNormal() bool {
+	return a == AccountRevenue
+}
+
+// Kind classifies a ledger transaction by the business event that posted it.
+type Kind string
+
+const (
+	KindPayment    Kind = "payment"
+	KindFee        Kind = "fee"
+	KindRefund     Kind = "refund"
+	KindSettlement Kind = "settlement" // a payment's net amount becomes available
+	KindPayout     Kind = "payout"
+)
+
+// Entry is one leg of a transaction. AmountMinor is positive for a debit and negative for a credit.
+type Entry struct {
+	Account     Account
+	AmountMinor int64
+}
+
+// Transfer returns the two entries that move amountMinor from the credited to the debited account.
+func Transfer(debit, credit Account, amountMinor int64) []Entry {
+	return []Entry{
+		{Account: debit, AmountMinor: amountMinor},
+		{Account: credit, AmountMinor: -amountMinor},
+	}
+}
+
+// Transaction is a set of entries posted together in one currency. The entries must sum to zero.
+// OrderID, PaymentID, RefundID and PayoutID link it to what caused it and may be empty.
+type Transaction struct {
+	BusinessID  string
+	Kind        Kind
+	Currency    string
+	OrderID     string
+	PaymentID   string
+	RefundID    string
+	PayoutID    string
+	Description string
+	Entries     []Entry
+}
+
+// Balance is an account's balance on its normal side: revenue grows with credits,
+// every other account with debits.
+type Balance struct {
+	Account      Account `json:"account"`
+	Currency     string  `json:"currency"`
+	Balance      string  `json:"balance"`
+	BalanceMinor int64   `json:"balance_minor"`
+}
+
+// BalancesResponse lists a business's account balances at a point in time.
+type BalancesResponse struct {
+	BusinessID string    `json:"business_id"`
+	AsOf       time.Time `json:"as_of"`
+	Balances   []Balance `json:"balances"`
+}
@@ -0,0 +1,21 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Enqueue renders tmpl with data and queues the email to the given address. It runs inside the
+// caller's transaction, so emails are only sent for changes that commit.
+func Enqueue(ctx context.Context, tx *sql.Tx, businessID, to string, tmpl Template, data any) error {
+	subject, body, err := render(tmpl, data)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO notification (business_id, template, recipient, subject, html)
+		VALUES ($1, $2, $3, $4, $5)`,
+		businessID, string(tmpl), to, subject, body,
+	)
+	return err
+}
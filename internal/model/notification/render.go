@@ -0,0 +1,45 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// templates holds one parsed set per Template: the shared layout plus the template's own
+// "subject" and "content" blocks.
+var templates = func() map[Template]*template.Template {
+	layout := template.Must(template.ParseFS(templateFS, "templates/layout.html"))
+	m := make(map[Template]*template.Template, len(Templates))
+	for _, name := range Templates {
+		t := template.Must(layout.Clone())
+		m[name] = template.Must(t.ParseFS(templateFS, "templates/"+string(name)+".html"))
+	}
+	return m
+}()
+
+// render returns the subject and HTML body of name rendered with data.
+func render(name Template, data any) (subject, body string, err error) {
+	t, ok := templates[name]
+	if !ok {
+		return "", "", fmt.Errorf("notification: unknown template %q", name)
+	}
+	var b bytes.Buffer
+	if err := t.ExecuteTemplate(&b, "subject", data); err != nil {
+		return "", "", err
+	}
+	// The subject is escaped as HTML text; headers need it plain and on one line.
+	subject = strings.Join(strings.Fields(html.UnescapeString(b.String())), " ")
+
+	b.Reset()
+	if err := t.ExecuteTemplate(&b, "layout", data); err != nil {
+		return "", "", err
+	}
+	return subject, b.String(), nil
+}
@@ -0,0 +1,117 @@
+package notification
+
+import (
+	"context"
+	"io"
+	"mime/quotedprintable"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/mail"
+)
+
+func TestRenderToFileSink(t *testing.T) {
+	paidAt := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	expiresAt := time.Date(2026, 3, 21, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    Template
+		data    any
+		subject string
+		want    []string // fragments the HTML body must contain
+	}{
+		{
+			name:    TemplateReceipt,
+			data:    OrderData{BusinessName: "Ada's Café", OrderID: "order-1", Description: "Two coffees", Amount: "12.50", Currency: "EUR", PaidAt: paidAt},
+			subject: "Your receipt from Ada's Café",
+			want:    []string{"Ada&#39;s Café received your payment", "Two coffees", "12.50 EUR", "14 Mar 2026 09:30 UTC", "order-1"},
+		},
+		{
+			name:    TemplateOrderPaid,
+			data:    OrderData{BusinessName: "Ada's Café", OrderID: "order-1", Amount: "12.50", Currency: "EUR", CustomerEmail: "bob@example.com", PaidAt: paidAt},
+			subject: "Order paid: 12.50 EUR",
+			want:    []string{"bob@example.com", "12.50 EUR", "order-1"},
+		},
+		{
+			name:    TemplatePaymentLink,
+			data:    PaymentLinkData{BusinessName: "Ada's Café", Description: "Two coffees", Amount: "12.50", Currency: "EUR", URL: "https://pay.example.com/l/abc?x=1&y=2", ExpiresAt: expiresAt},
+			subject: "Ada's Café requests a payment of 12.50 EUR",
+			want:    []string{`href="https://pay.example.com/l/abc?x=1&amp;y=2"`, "21 Mar 2026 09:30 UTC"},
+		},
+		{
+			name:    TemplateInvitation,
+			data:    InvitationData{BusinessName: "Ada's Café", InviterName: "<b>Ada</b>", Role: "admin", URL: "https://app.example.com/invitations/abc", ExpiresAt: expiresAt},
+			subject: "You're invited to join Ada's Café on Payway",
+			want:    []string{"&lt;b&gt;Ada&lt;/b&gt; invited you", "as admin", `href="https://app.example.com/invitations/abc"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.name), func(t *testing.T) {
+			subject, body, err := render(tt.name, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if subject != tt.subject {
+				t.Fatalf("subject = %q, want %q", subject, tt.subject)
+			}
+
+			sink := &mail.FileSink{Dir: t.TempDir(), From: "Payway <no-reply@payway.test>"}
+			msg := mail.Message{ID: "msg-" + string(tt.name), To: "customer@example.com", Subject: subject, HTML: body}
+			if err := sink.Send(context.Background(), msg); err != nil {
+				t.Fatal(err)
+			}
+
+			htmlFile := readSinkFile(t, sink.Dir, ".html")
+			for _, want := range tt.want {
+				if !strings.Contains(htmlFile, want) {
+					t.Errorf("body does not contain %q:\n%s", want, htmlFile)
+				}
+			}
+
+			eml := readSinkFile(t, sink.Dir, ".eml")
+			header, encoded, ok := strings.Cut(eml, "\r\n\r\n")
+			if !ok {
+				t.Fatalf("message has no body:\n%s", eml)
+			}
+			for _, want := range []string{"To: customer@example.com\r\n", "Message-ID: <msg-" + string(tt.name) + "@payway>\r\n", "Content-Type: text/html; charset=utf-8\r\n"} {
+				if !strings.Contains(header, want) {
+					t.Errorf("header does not contain %q:\n%s", want, header)
+				}
+			}
+			decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(encoded)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Quoted-printable encodes line breaks as CRLF.
+			if strings.ReplaceAll(string(decoded), "\r\n", "\n") != htmlFile {
+				t.Errorf(".eml body differs from .html file")
+			}
+		})
+	}
+}
+
+func TestRenderUnknownTemplate(t *testing.T) {
+	if _, _, err := render("missing", nil); err == nil {
+		t.Fatal("render of an unknown template succeeded")
+	}
+}
+
+// readSinkFile returns the only file in dir with the given extension.
+func readSinkFile(t *testing.T, dir, ext string) string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("found %d %s files in the sink, want 1", len(matches), ext)
+	}
+	raw, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(raw)
+}
@@ -0,0 +1,201 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"backend/internal/mail"
+)
+
+const (
+	// maxAttempts is how often an email is tried before it is marked failed.
+	maxAttempts = 8
+	// batchSize bounds how many emails a sender claims at once.
+	batchSize = 20
+	// lease is how long a claimed email is hidden from other senders while it is being sent.
+	lease = 2 * time.Minute
+	// sendTimeout bounds the delivery of a single email.
+	sendTimeout = 30 * time.Second
+	// drainTimeout is how long an email in flight may finish after shutdown begins.
+	drainTimeout = 5 * time.Second
+)
+
+// RunSender delivers queued emails through mailer every interval until ctx is cancelled.
+// Several replicas may run it concurrently; claimed emails are leased to one of them.
+// On cancellation, an email in flight gets drainTimeout to finish and the rest of the claimed
+// batch is released so other replicas pick it up without waiting for the lease to expire.
+func RunSender(ctx context.Context, db *sql.DB, mailer mail.Mailer, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			for ctx.Err() == nil {
+				n, err := sendDue(ctx, db, mailer)
+				if err != nil {
+					slog.Warn("send notifications failed", slog.Any("err", err))
+				}
+				if err != nil || n < batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// pendingNotification is a claimed email with everything needed to send it.
+type pendingNotification struct {
+	id       string
+	attempts int
+	template string
+	msg      mail.Message
+}
+
+// sendDue claims a batch of due emails and sends them. It returns the batch size.
+func sendDue(ctx context.Context, db *sql.DB, mailer mail.Mailer) (int, error) {
+	rows, err := db.QueryContext(ctx, `
+		UPDATE notification
+		SET next_attempt_at = now() + make_interval(secs => $1)
+		WHERE id IN (
+			SELECT id FROM notification
+			WHERE status = $2 AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, attempts, template, recipient, subject, html`,
+		lease.Seconds(), statusPending, batchSize,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var batch []pendingNotification
+	for rows.Next() {
+		var n pendingNotification
+		if err := rows.Scan(&n.id, &n.attempts, &n.template, &n.msg.To, &n.msg.Subject, &n.msg.HTML); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		n.msg.ID = n.id
+		batch = append(batch, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	work, stop := drainContext(ctx, drainTimeout)
+	defer stop()
+	var unfinished []string
+	for _, n := range batch {
+		if ctx.Err() != nil {
+			unfinished = append(unfinished, n.id)
+			continue
+		}
+		if err := deliver(work, db, mailer, n); err != nil {
+			if work.Err() != nil {
+				unfinished = append(unfinished, n.id)
+				continue
+			}
+			slog.Error("record notification attempt failed", slog.String("notification_id", n.id), slog.Any("err", err))
+		}
+	}
+	if len(unfinished) > 0 {
+		if err := releaseLeases(ctx, db, unfinished); err != nil {
+			slog.Error("release notification leases failed", slog.Int("notifications", len(unfinished)), slog.Any("err", err))
+		}
+	}
+	return len(batch), nil
+}
+
+// drainContext returns a context that outlives ctx by grace, so work in flight when ctx is
+// cancelled can finish. stop must be called to release its resources.
+func drainContext(ctx context.Context, grace time.Duration) (work context.Context, stop func()) {
+	work, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	unregister := context.AfterFunc(ctx, func() { time.AfterFunc(grace, cancel) })
+	return work, func() {
+		unregister()
+		cancel()
+	}
+}
+
+// releaseLeases makes claimed emails that were not attempted due again immediately.
+func releaseLeases(ctx context.Context, db *sql.DB, ids []string) error {
+	// ctx may already be cancelled when shutting down; the release itself must still run.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), drainTimeout)
+	defer cancel()
+	_, err := db.ExecContext(ctx, `
+		UPDATE notification
+		SET next_attempt_at = now()
+		WHERE id = ANY($1::text[]::uuid[]) AND status = $2`,
+		ids, statusPending,
+	)
+	return err
+}
+
+// deliver sends one email and records the outcome. Failures are retried with exponential
+// backoff until maxAttempts is reached.
+func deliver(ctx context.Context, db *sql.DB, mailer mail.Mailer, n pendingNotification) error {
+	attempt := n.attempts + 1
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	sendErr := mailer.Send(sendCtx, n.msg)
+	cancel()
+	if ctx.Err() != nil {
+		// Cut short by shutdown; the caller releases the lease instead of recording an attempt.
+		return ctx.Err()
+	}
+
+	var err error
+	switch {
+	case sendErr == nil:
+		_, err = db.ExecContext(ctx, `
+			UPDATE notification
+			SET status = $2, attempts = $3, last_error = NULL, sent_at = now()
+			WHERE id = $1`,
+			n.id, statusSent, attempt,
+		)
+	case attempt >= maxAttempts:
+		slog.Error("notification failed permanently",
+			slog.String("notification_id", n.id), slog.String("template", n.template), slog.Any("err", sendErr))
+		_, err = db.ExecContext(ctx, `
+			UPDATE notification
+			SET status = $2, attempts = $3, last_error = $4
+			WHERE id = $1`,
+			n.id, statusFailed, attempt, sendErr.Error(),
+		)
+	default:
+		slog.Warn("send notification failed, will retry",
+			slog.String("notification_id", n.id), slog.Int("attempt", attempt), slog.Any("err", sendErr))
+		_, err = db.ExecContext(ctx, `
+			UPDATE notification
+			SET attempts = $2, last_error = $3, next_attempt_at = now() + make_interval(secs => $4)
+			WHERE id = $1`,
+			n.id, attempt, sendErr.Error(), retryDelay(attempt).Seconds(),
+		)
+	}
+	return err
+}
+
+// retryDelay is the wait after the given failed attempt: about 30s, 1m, 2m, ... capped at 1h, with jitter.
+func retryDelay(attempt int) time.Duration {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = 30 * time.Second
+	b.Multiplier = 2
+	b.MaxInterval = time.Hour
+	b.RandomizationFactor = 0.2
+	b.MaxElapsedTime = 0
+	b.Reset()
+
+	d := b.NextBackOff()
+	for i := 1; i < attempt; i++ {
+		d = b.NextBackOff()
+	}
+	return d
+}
@@ -0,0 +1,53 @@
+package notification
+
+import "time"
+
+// Template names an email template in templates/<name>.html.
+type Template string
+
+const (
+	TemplateReceipt     Template = "receipt"      // sent to the customer when their order is paid
+	TemplateOrderPaid   Template = "order_paid"   // sent to the business's owners and admins when an order is paid
+	TemplatePaymentLink Template = "payment_link" // sends an order's payment link to its customer
+	TemplateInvitation  Template = "invitation"   // sent to the invitee with the link to accept
+)
+
+// Templates lists every template; each is parsed at startup.
+var Templates = []Template{TemplateReceipt, TemplateOrderPaid, TemplatePaymentLink, TemplateInvitation}
+
+// OrderData is rendered by the receipt and order_paid templates.
+type OrderData struct {
+	BusinessName  string
+	OrderID       string
+	Description   string
+	Amount        string // decimal string, e.g. "12.50"
+	Currency      string
+	CustomerEmail string
+	PaidAt        time.Time
+}
+
+// PaymentLinkData is rendered by the payment_link template.
+type PaymentLinkData struct {
+	BusinessName string
+	Description  string
+	Amount       string
+	Currency     string
+	URL          string
+	ExpiresAt    time.Time
+}
+
+// InvitationData is rendered by the invitation template.
+type InvitationData struct {
+	BusinessName string
+	InviterName  string
+	Role         string
+	URL          string
+	ExpiresAt    time.Time
+}
+
+// Notification statuses.
+const (
+	statusPending = "pending"
+	statusSent    = "sent"
+	statusFailed  = "failed"
+)
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -12,38 +13,51 @@ import (
 
 	"backend/internal/auth"
 	httpx "backend/internal/httpx"
+	"backend/internal/model/business"
 	"backend/internal/model/businessuser"
+	"backend/internal/model/customer"
+	"backend/internal/model/webhook"
+	"backend/internal/money"
 
 	"github.com/go-chi/chi/v5"
 )
 
 // OrderPayload matches the fields the client sends
 // Adjust types/validation as your API evolves.
+// Amount is a decimal given as a JSON number or string (e.g. 12.5 or "12.50") and must not
+// have more decimal places than the currency allows. Currency defaults to the business default
+// and must be one of the business's enabled currencies.
+// The order is linked to CustomerID, or to the business's customer with Email, who is created
+// when there is none; at most one of the two may be given.
 type OrderPayload struct {
-	Amount      float64 `json:"amount"`
-	Description string  `json:"description"`
-	Email       string  `json:"email"`
-	Currency    string  `json:"currency"`
-	BusinessID  string  `json:"business_id"`
+	Amount      json.Number `json:"amount" swaggertype:"string" example:"12.50"`
+	Description string      `json:"description"`
+	CustomerID  string      `json:"customer_id"`
+	Email       string      `json:"email"`
+	Currency    string      `json:"currency"`
+	BusinessID  string      `json:"business_id"`
 }
 
 // attachCreateRoutes registers the create (POST) endpoint.
-func attachCreateRoutes(r chi.Router, db *sql.DB) {
-	r.Post("/", func(w http.ResponseWriter, r *http.Request) { createOrder(db, w, r) })
+// linkTTL is how long the payment link of a new order stays valid.
+func attachCreateRoutes(r chi.Router, db *sql.DB, linkTTL time.Duration) {
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) { createOrder(db, linkTTL, w, r) })
 }
 
 // createOrder handles POST /api/orders
 //
 // @Summary      Create an order
-// @Description  Creates a new order and returns its ID
+// @Description  Creates a new order and returns it. If business_id is omitted and the authenticated user belongs to exactly one business, that business is used; otherwise 400 lists the candidate businesses.
 // @Tags         orders
 // @Accept       json
 // @Produce      json
 // @Param        payload  body      OrderPayload          true  "Order payload"
 // @Success      200      {object}  CreateOrderResponse
-// @Failure      400      {object}  ErrorResponse
+// @Failure      400      {object}  businessuser.AmbiguousBusinessResponse
+// @Failure      403      {object}  businessuser.MissingPermissionResponse
+// @Failure      409      {object}  ErrorResponse  "Business is archived"
 // @Router       /api/orders [post]
-func createOrder(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+func createOrder(db *sql.DB, linkTTL time.Duration, w http.ResponseWriter, r *http.Request) {
 
 	u, ok := auth.FirebaseUser(w, r)
 	if !ok {
@@ -58,21 +72,38 @@ func createOrder(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := normalizeAndValidate(&p); err != nil {
-		slog.Error("normalize and validate error", slog.Any("err", err))
-		httpx.WriteBadRequest(w)
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	businessID := strings.TrimSpace(p.BusinessID)
-
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	if !businessuser.AssertUserBelongsToBusiness(ctx, db, w, businessID, u) {
+	businessID, ok := businessuser.ResolveBusinessID(ctx, db, w, p.BusinessID, u)
+	if !ok {
+		return
+	}
+	p.BusinessID = businessID
+
+	if !businessuser.AssertPermission(ctx, db, w, businessID, u, businessuser.PermOrdersCreate) {
+		return
+	}
+
+	if !business.AssertActive(ctx, db, w, businessID) {
+		return
+	}
+
+	currency, ok := business.ResolveCurrency(ctx, db, w, businessID, p.Currency)
+	if !ok {
+		return
+	}
+	amount, err := parseAmount(p.Amount, currency)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	order, ok := insertOrder(w, ctx, db, p, u)
+	order, ok := insertOrder(w, ctx, db, p, amount, u, linkTTL)
 	if !ok {
 		return
 	}
@@ -81,42 +112,98 @@ func createOrder(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(order)
 }
 
-// normalizeAndValidate uppercases currency and performs minimal validations.
+// normalizeAndValidate uppercases currency, normalizes the email and performs minimal validations.
+// An empty currency is allowed and later replaced by the business default;
+// an empty business_id is resolved later from the user's memberships.
 func normalizeAndValidate(p *OrderPayload) error {
-	if p.Amount <= 0 {
-		return errors.New("amount must be > 0")
-	}
-	if strings.TrimSpace(p.BusinessID) == "" {
-		return errors.New("business_id is required")
+	p.CustomerID = strings.TrimSpace(p.CustomerID)
+	if p.Email = strings.TrimSpace(p.Email); p.Email != "" {
+		if p.CustomerID != "" {
+			return errors.New("give either customer_id or email, not both")
+		}
+		email, err := customer.NormalizeEmail(p.Email)
+		if err != nil {
+			return err
+		}
+		p.Email = email
 	}
 	p.Currency = strings.ToUpper(strings.TrimSpace(p.Currency))
-	if len(p.Currency) != 3 {
-		return errors.New("currency must be a 3-letter code")
+	if p.Currency != "" {
+		if _, ok := money.LookupCurrency(p.Currency); !ok {
+			return fmt.Errorf("currency %q is not a supported ISO 4217 currency", p.Currency)
+		}
 	}
 	return nil
 }
 
-// insertOrder performs the INSERT and returns the created Order. It resolves created_by via firebase_id in a subquery.
-func insertOrder(w http.ResponseWriter, ctx context.Context, db *sql.DB, p OrderPayload, u *auth.User) (Order, bool) {
-	query := `INSERT INTO "order" (business_id, created_by, amount, description, customer_email, currency)
-		VALUES ($1, (SELECT id FROM "user" WHERE firebase_id = $2), $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at, business_id, created_by, status, amount, currency, description, customer_email`
+// parseAmount converts the payload amount into positive minor units of currency.
+func parseAmount(raw json.Number, currency string) (money.Amount, error) {
+	amount, err := money.Parse(raw.String(), currency)
+	if err != nil {
+		return money.Amount{}, err
+	}
+	if !amount.IsPositive() {
+		return money.Amount{}, errors.New("amount must be > 0")
+	}
+	return amount, nil
+}
 
-	var ord Order
-	var descNS, emailNS sql.NullString
-	if err := db.QueryRowContext(ctx, query, p.BusinessID, u.UID, p.Amount, nullIfEmpty(p.Description), nullIfEmpty(p.Email), p.Currency).
-		Scan(&ord.ID, &ord.CreatedAt, &ord.UpdatedAt, &ord.BusinessID, &ord.CreatedBy, &ord.Status, &ord.Amount, &ord.Currency, &descNS, &emailNS); err != nil {
+// insertOrder performs the INSERT and returns the created Order. It resolves created_by via firebase_id in a subquery,
+// links or creates the customer, and records the initial status in order_status_history and the order.created webhook event within the same transaction.
+// The order's payment link expires linkTTL from now.
+func insertOrder(w http.ResponseWriter, ctx context.Context, db *sql.DB, p OrderPayload, amount money.Amount, u *auth.User, linkTTL time.Duration) (Order, bool) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("create order begin tx error", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Order{}, false
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	customerID := p.CustomerID
+	switch {
+	case customerID != "":
+		err = customer.Check(ctx, tx, p.BusinessID, customerID)
+	case p.Email != "":
+		customerID, err = customer.FindOrCreateByEmail(ctx, tx, p.BusinessID, p.Email)
+	}
+	if errors.Is(err, customer.ErrNotFound) {
+		httpx.WriteErr(w, http.StatusBadRequest, "customer_id is not a customer of this business")
+		return Order{}, false
+	} else if err != nil {
+		slog.Error("create order customer error", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Order{}, false
+	}
+
+	query := `INSERT INTO "order" (business_id, created_by, amount_minor, description, customer_id, currency, payment_link_token, payment_link_expires_at)
+		VALUES ($1, (SELECT id FROM "user" WHERE firebase_id = $2), $3, $4, $5, $6, $7, now() + make_interval(secs => $8))
+		RETURNING ` + orderColumns
+
+	ord, err := scanOrder(tx.QueryRowContext(ctx, query, p.BusinessID, u.UID, amount.Minor, nullIfEmpty(p.Description), nullIfEmpty(customerID), amount.Currency,
+		newPaymentLinkToken(), linkTTL.Seconds()))
+	if err != nil {
 		slog.Error("create order insert error", slog.Any("err", err))
 		httpx.WriteInternalServerError(w)
 		return Order{}, false
 	}
-	if descNS.Valid {
-		d := descNS.String
-		ord.Description = &d
+
+	if err := recordStatus(ctx, tx, ord.ID, nil, ord.Status, Actor{Kind: ActorUser, UserID: ord.CreatedBy}, ""); err != nil {
+		slog.Error("create order history error", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Order{}, false
 	}
-	if emailNS.Valid {
-		e := emailNS.String
-		ord.CustomerEmail = &e
+
+	if err := webhook.Enqueue(ctx, tx, ord.BusinessID, webhook.EventOrderCreated, ord); err != nil {
+		slog.Error("create order webhook error", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Order{}, false
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("create order commit error", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Order{}, false
 	}
 	return ord, true
 }
@@ -0,0 +1,192 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+	"backend/internal/money"
+	"backend/internal/pagination"
+)
+
+// CustomerTotal sums a customer's orders in one currency over their lifetime.
+// Paid counts captured payments and Net is Paid minus Refunded.
+type CustomerTotal struct {
+	Currency      string `json:"currency"`
+	OrderCount    int    `json:"order_count"`
+	Paid          string `json:"paid"`
+	PaidMinor     int64  `json:"paid_minor"`
+	Refunded      string `json:"refunded"`
+	RefundedMinor int64  `json:"refunded_minor"`
+	Net           string `json:"net"`
+	NetMinor      int64  `json:"net_minor"`
+}
+
+// CustomerOrdersResponse is one page of a customer's orders, newest first, with their lifetime
+// totals. NextCursor is null on the last page.
+type CustomerOrdersResponse struct {
+	CustomerID string          `json:"customer_id"`
+	Totals     []CustomerTotal `json:"totals"`
+	Orders     []Order         `json:"orders"`
+	NextCursor *string         `json:"next_cursor"`
+}
+
+// CustomerOrders serves GET /api/customers/{customerID}/orders; the customer routes mount it.
+func CustomerOrders(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) { getCustomerOrders(db, w, r) }
+}
+
+// getCustomerOrders handles GET /api/customers/{customerID}/orders
+//
+// @Summary      List a customer's orders
+// @Description  Returns a page of the customer's orders, newest first, and their lifetime totals per currency. Requires the orders:read_all permission. Pass next_cursor back as cursor to fetch the following page.
+// @Tags         customers
+// @Produce      json
+// @Param        customerID  path      string  true   "Customer ID"
+// @Param        limit       query     int     false  "Page size (1-200, default 50)"
+// @Param        cursor      query     string  false  "next_cursor from the previous page"
+// @Success      200         {object}  CustomerOrdersResponse
+// @Failure      400         {object}  ErrorResponse
+// @Failure      403         {object}  businessuser.MissingPermissionResponse
+// @Failure      404         {object}  ErrorResponse
+// @Router       /api/customers/{customerID}/orders [get]
+func getCustomerOrders(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	customerID := chi.URLParam(r, "customerID")
+	logger := slog.Default().With(
+		slog.String("component", "orders"),
+		slog.String("op", "getCustomerOrders"),
+		slog.String("customer_id", customerID),
+	)
+
+	limit, after, err := pagination.Parse(r.URL.Query(), false)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var afterAt *time.Time
+	var afterID *string
+	if after != nil {
+		afterAt, afterID = &after.CreatedAt, &after.ID
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if _, err := uuid.Parse(customerID); err != nil {
+		httpx.WriteErr(w, http.StatusNotFound, "customer not found")
+		return
+	}
+	var bizID string
+	err = db.QueryRowContext(ctx, `SELECT business_id FROM customer WHERE id = $1`, customerID).Scan(&bizID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusNotFound, "customer not found")
+		return
+	} else if err != nil {
+		logger.Error("query customer failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	if !businessuser.AssertPermission(ctx, db, w, bizID, u, businessuser.PermOrdersReadAll) {
+		return
+	}
+
+	totals, err := customerTotals(ctx, db, customerID)
+	if err != nil {
+		logger.Error("query customer totals failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+orderColumns+`, `+creatorNameColumn+`
+		FROM "order" o
+		WHERE o.customer_id = $1
+			AND ($2::timestamptz IS NULL OR (o.created_at, o.id) < ($2, $3::uuid))
+		ORDER BY o.created_at DESC, o.id DESC
+		LIMIT $4`,
+		customerID, afterAt, afterID, limit+1,
+	)
+	if err != nil {
+		logger.Error("query customer orders failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+
+	orders := make([]Order, 0)
+	for rows.Next() {
+		var creatorName *string
+		o, err := scanOrder(rows, &creatorName)
+		if err != nil {
+			logger.Error("scan order row failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		o.CreatedByName = creatorName
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("rows error after iteration", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	resp := CustomerOrdersResponse{CustomerID: customerID, Totals: totals, Orders: orders}
+	if len(orders) > limit {
+		last := orders[limit-1]
+		next := pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+		resp.Orders = orders[:limit]
+		resp.NextCursor = &next
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// customerTotals sums every order of the customer per currency.
+func customerTotals(ctx context.Context, db *sql.DB, customerID string) ([]CustomerTotal, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT o.currency, count(*),
+			COALESCE(sum((SELECT p.amount_minor FROM payment p WHERE p.order_id = o.id AND p.status = $2)), 0),
+			COALESCE(sum((SELECT sum(rf.amount_minor) FROM refund rf WHERE rf.order_id = o.id AND rf.status = $3)), 0)
+		FROM "order" o
+		WHERE o.customer_id = $1
+		GROUP BY o.currency
+		ORDER BY o.currency`,
+		customerID, PaymentSucceeded, RefundSucceeded,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make([]CustomerTotal, 0)
+	for rows.Next() {
+		var t CustomerTotal
+		if err := rows.Scan(&t.Currency, &t.OrderCount, &t.PaidMinor, &t.RefundedMinor); err != nil {
+			return nil, err
+		}
+		t.NetMinor = t.PaidMinor - t.RefundedMinor
+		t.Paid = money.FromMinor(t.PaidMinor, t.Currency).String()
+		t.Refunded = money.FromMinor(t.RefundedMinor, t.Currency).String()
+		t.Net = money.FromMinor(t.NetMinor, t.Currency).String()
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
@@ -0,0 +1,107 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// expireBatchSize bounds how many orders one expiry pass handles.
+const expireBatchSize = 100
+
+// RunExpirer moves orders that stayed pending for longer than ttl to expired, every interval until
+// ctx is cancelled. Orders with a payment in flight are left alone until it resolves. Several
+// replicas may run it concurrently.
+func RunExpirer(ctx context.Context, db *sql.DB, interval, ttl time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if n, err := expireDue(ctx, db, ttl); err != nil {
+				slog.Warn("expire orders failed", slog.Any("err", err))
+			} else if n > 0 {
+				slog.Info("orders expired", slog.Int("count", n))
+			}
+		}
+	}
+}
+
+// expireDue expires a batch of overdue pending orders and returns how many it expired.
+func expireDue(ctx context.Context, db *sql.DB, ttl time.Duration) (int, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT o.id
+		FROM "order" o
+		WHERE o.status = $1 AND o.created_at <= now() - make_interval(secs => $2)
+			AND NOT EXISTS (SELECT 1 FROM payment p WHERE p.order_id = o.id AND p.status IN ($3, $4))
+		ORDER BY o.created_at
+		LIMIT $5`,
+		StatusPending, ttl.Seconds(), PaymentPending, PaymentProcessing, expireBatchSize,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, id := range ids {
+		expired, err := expireOrder(ctx, db, id, ttl)
+		if err != nil {
+			slog.Error("expire order failed", slog.String("order_id", id), slog.Any("err", err))
+			continue
+		}
+		if expired {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// expireOrder expires one order unless it was paid, cancelled or got a payment in flight since it
+// was selected.
+func expireOrder(ctx context.Context, db *sql.DB, orderID string, ttl time.Duration) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var inFlight bool
+	if err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM payment p WHERE p.order_id = o.id AND p.status IN ($2, $3))
+		FROM "order" o
+		WHERE o.id = $1
+		FOR UPDATE`,
+		orderID, PaymentPending, PaymentProcessing,
+	).Scan(&inFlight); err != nil {
+		return false, err
+	}
+	if inFlight {
+		return false, nil
+	}
+
+	_, err = transition(ctx, tx, orderID, StatusExpired, Actor{Kind: ActorSystem}, "unpaid for "+ttl.String())
+	var te *TransitionError
+	if errors.As(err, &te) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
@@ -0,0 +1,136 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"backend/internal/auth"
+	"backend/internal/dbtest"
+	"backend/internal/payment"
+	"backend/internal/payment/simulator"
+)
+
+// stubVerifier accepts the tokens it was given, each standing for one user.
+type stubVerifier map[string]*auth.User
+
+func (v stubVerifier) VerifyIDToken(_ context.Context, raw string) (*auth.User, error) {
+	if u, ok := v[raw]; ok {
+		return u, nil
+	}
+	return nil, errors.New("unknown token")
+}
+
+// testEnv is a migrated database with the order routes mounted behind token authentication.
+type testEnv struct {
+	db      *sql.DB
+	sim     *simulator.Simulator
+	users   stubVerifier
+	handler http.Handler
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	db := dbtest.Open(t)
+	sim := simulator.New(simulator.Config{Secret: []byte("test-secret"), Delay: time.Hour, FeeBPS: 290, FeeFixedMinor: 30})
+	payments, err := payment.NewRegistry(simulator.Name, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := &testEnv{db: db, sim: sim, users: stubVerifier{}}
+	env.handler = auth.NewFirebaseMiddleware(env.users, nil)(Routes(db, payments, time.Hour, "http://localhost"))
+	return env
+}
+
+// newBusiness creates a business accepting currency and returns its ID.
+func (e *testEnv) newBusiness(t *testing.T, currency string) string {
+	t.Helper()
+	var id string
+	dbtest.QueryValue(t, e.db, &id, `INSERT INTO business (name, default_currency) VALUES ('Test business', $1) RETURNING id`, currency)
+	dbtest.Exec(t, e.db, `INSERT INTO business_currency (business_id, currency) VALUES ($1, $2)`, id, currency)
+	return id
+}
+
+// newMember creates a user with the given role in the business and returns their internal ID and
+// the bearer token that authenticates them.
+func (e *testEnv) newMember(t *testing.T, businessID, role string) (userID, token string) {
+	t.Helper()
+	firebaseID := role + "-" + uuid.NewString()
+	dbtest.QueryValue(t, e.db, &userID, `INSERT INTO "user" (firebase_id, name) VALUES ($1, $2) RETURNING id`, firebaseID, role)
+	dbtest.Exec(t, e.db, `INSERT INTO business_user (business_id, user_id, role) VALUES ($1, $2, $3)`, businessID, userID, role)
+	token = "token-" + firebaseID
+	e.users[token] = &auth.User{UID: firebaseID}
+	return userID, token
+}
+
+// newOrder inserts a pending order with its creation history entry.
+func (e *testEnv) newOrder(t *testing.T, businessID, createdBy string, amountMinor int64, currency string) Order {
+	t.Helper()
+	ord, err := scanOrder(e.db.QueryRowContext(context.Background(), `
+		INSERT INTO "order" (business_id, created_by, amount_minor, currency, payment_link_token, payment_link_expires_at)
+		VALUES ($1, $2, $3, $4, $5, now() + interval '1 hour')
+		RETURNING `+orderColumns,
+		businessID, createdBy, amountMinor, currency, newPaymentLinkToken(),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbtest.Exec(t, e.db, `
+		INSERT INTO order_status_history (order_id, from_status, to_status, actor_kind, actor_id)
+		VALUES ($1, NULL, $2, 'user', $3)`,
+		ord.ID, ord.Status, createdBy)
+	return ord
+}
+
+// do sends a request as the user behind token and returns the response.
+func (e *testEnv) do(t *testing.T, token, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode unmarshals a response body, failing the test on error.
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+// orderStatus returns the stored status of an order.
+func (e *testEnv) orderStatus(t *testing.T, orderID string) Status {
+	t.Helper()
+	var s Status
+	dbtest.QueryValue(t, e.db, &s, `SELECT status FROM "order" WHERE id = $1`, orderID)
+	return s
+}
+
+// historyCount returns how many status history rows an order has.
+func (e *testEnv) historyCount(t *testing.T, orderID string) int {
+	t.Helper()
+	var n int
+	dbtest.QueryValue(t, e.db, &n, `SELECT count(*) FROM order_status_history WHERE order_id = $1`, orderID)
+	return n
+}
+
+// setStatus moves an order to s directly, bypassing the state machine.
+func (e *testEnv) setStatus(t *testing.T, orderID string, s Status) {
+	t.Helper()
+	dbtest.Exec(t, e.db, `UPDATE "order" SET status = $2 WHERE id = $1`, orderID, s)
+}
@@ -4,33 +4,58 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 
 	"backend/internal/auth"
 	"backend/internal/httpx"
 	"backend/internal/model/businessuser"
+	"backend/internal/pagination"
 )
 
-// attachGetRoutes registers the list (GET) endpoint.
+// attachGetRoutes registers the list and single-order (GET) endpoints.
 func attachGetRoutes(r chi.Router, db *sql.DB) {
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) { getOrders(db, w, r) })
+	r.Get("/{orderID}", func(w http.ResponseWriter, r *http.Request) { getOrder(db, w, r) })
+}
+
+// ListOrdersResponse is one page of orders. NextCursor is null on the last page.
+type ListOrdersResponse struct {
+	Orders     []Order `json:"orders"`
+	NextCursor *string `json:"next_cursor"`
 }
 
 // getOrders handles GET /api/orders?business_id=...
 //
 // @Summary      List orders by business ID
-// @Description  Returns all orders for the provided business_id. If business_id is omitted and the authenticated user belongs to exactly one business, that business will be used automatically. If the user belongs to zero or more than one business, an error is returned.
+// @Description  Returns a page of orders for the provided business_id, newest first by default. scope=mine (default) returns the caller's own orders; scope=business returns every member's orders and requires the orders:read_all permission. If business_id is omitted and the authenticated user belongs to exactly one business, that business will be used automatically. If the user belongs to zero or more than one business, an error is returned. Pass next_cursor back as cursor to fetch the following page.
 // @Tags         orders
 // @Accept       json
 // @Produce      json
-// @Param        business_id  query     string  false  "Business ID"
-// @Success      200          {array}   object
-// @Failure      400          {object}  ErrorResponse
+// @Param        business_id     query     string  false  "Business ID"
+// @Param        scope           query     string  false  "mine (default) or business"
+// @Param        created_by      query     string  false  "Creator user ID (requires scope=business)"
+// @Param        limit           query     int     false  "Page size (1-200, default 50)"
+// @Param        cursor          query     string  false  "next_cursor from the previous page"
+// @Param        sort            query     string  false  "created_at or -created_at (default)"
+// @Param        status          query     string  false  "Comma-separated statuses"
+// @Param        currency        query     string  false  "ISO 4217 currency code"
+// @Param        amount_min      query     string  false  "Minimum amount (decimal, requires currency)"
+// @Param        amount_max      query     string  false  "Maximum amount (decimal, requires currency)"
+// @Param        created_from    query     string  false  "Created at or after (RFC 3339)"
+// @Param        created_to      query     string  false  "Created before (RFC 3339)"
+// @Param        customer_id     query     string  false  "Customer ID"
+// @Param        customer_email  query     string  false  "Customer email (case-insensitive)"
+// @Success      200             {object}  ListOrdersResponse
+// @Failure      400             {object}  businessuser.AmbiguousBusinessResponse
+// @Failure      403             {object}  businessuser.MissingPermissionResponse
 // @Router       /api/orders [get]
 func getOrders(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 
@@ -45,23 +70,43 @@ func getOrders(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 		slog.String("op", "getOrders"),
 	)
 
-	// Resolve internal user UUID from Firebase UID
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
 
-	bizID := strings.TrimSpace(r.URL.Query().Get("business_id"))
+	params, err := parseListParams(r.URL.Query())
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	if !businessuser.AssertUserBelongsToBusiness(ctx, db, w, bizID, u) {
+	bizID, ok := businessuser.ResolveBusinessID(ctx, db, w, r.URL.Query().Get("business_id"), u)
+	if !ok {
 		return
 	}
 
+	perm := businessuser.PermOrdersRead
+	if params.Scope == scopeBusiness {
+		perm = businessuser.PermOrdersReadAll
+	}
+	if !businessuser.AssertPermission(ctx, db, w, bizID, u, perm) {
+		return
+	}
+
+	conds := []string{`o.business_id = $1::uuid`}
+	args := []any{bizID}
+	if params.Scope == scopeMine {
+		conds = append(conds, `o.created_by = (SELECT id FROM "user" WHERE firebase_id = $2)`)
+		args = append(args, u.UID)
+	}
+	conds, args = params.where(conds, args)
+	args = append(args, params.Limit+1)
 	rows, err := db.QueryContext(ctx, `
-		SELECT id, created_at, updated_at, business_id, created_by, status, amount, description, customer_email, currency
-		FROM "order"
-		WHERE business_id = $1::uuid AND created_by = (SELECT id FROM "user" WHERE firebase_id = $2)
-		ORDER BY created_at DESC`,
-		bizID,
-		u.UID,
+		SELECT `+orderColumns+`, `+creatorNameColumn+`
+		FROM "order" o
+		WHERE `+strings.Join(conds, " AND ")+`
+		ORDER BY `+params.orderBy()+`
+		LIMIT $`+strconv.Itoa(len(args)),
+		args...,
 	)
 	if err != nil {
 		logger.Error("query orders failed", slog.String("business_id", bizID), slog.Any("err", err))
@@ -72,20 +117,14 @@ func getOrders(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 
 	orders := make([]Order, 0)
 	for rows.Next() {
-		var o Order
-		var desc sql.NullString
-		var email sql.NullString
-		if err := rows.Scan(&o.ID, &o.CreatedAt, &o.UpdatedAt, &o.BusinessID, &o.CreatedBy, &o.Status, &o.Amount, &desc, &email, &o.Currency); err != nil {
+		var creatorName *string
+		o, err := scanOrder(rows, &creatorName)
+		if err != nil {
 			logger.Error("scan order row failed", slog.Any("err", err))
 			httpx.WriteInternalServerError(w)
 			return
 		}
-		if desc.Valid {
-			o.Description = &desc.String
-		}
-		if email.Valid {
-			o.CustomerEmail = &email.String
-		}
+		o.CreatedByName = creatorName
 		orders = append(orders, o)
 	}
 	if err := rows.Err(); err != nil {
@@ -94,6 +133,103 @@ func getOrders(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	resp := ListOrdersResponse{Orders: orders}
+	if len(orders) > params.Limit {
+		last := orders[params.Limit-1]
+		next := pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID, Ascending: params.Ascending}.Encode()
+		resp.Orders = orders[:params.Limit]
+		resp.NextCursor = &next
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// getOrder handles GET /api/orders/{orderID}
+//
+// @Summary      Get an order
+// @Description  Returns a single order. Orders of businesses the user does not belong to are reported as not found. Orders created by another member require the orders:read_all permission.
+// @Tags         orders
+// @Produce      json
+// @Param        orderID  path      string  true  "Order ID"
+// @Success      200      {object}  Order
+// @Failure      403      {object}  businessuser.MissingPermissionResponse
+// @Failure      404      {object}  ErrorResponse
+// @Router       /api/orders/{orderID} [get]
+func getOrder(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	ord, ok := loadAuthorizedOrder(ctx, db, w, chi.URLParam(r, "orderID"), u, businessuser.PermOrdersRead)
+	if !ok {
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(orders)
+	_ = json.NewEncoder(w).Encode(ord)
+}
+
+// loadAuthorizedOrder fetches an order and checks the user's role in the order's business grants perm.
+// Unknown IDs and orders of other businesses both yield 404 so that order IDs cannot be probed;
+// members lacking perm get 403 naming it. Reading (PermOrdersRead) an order another member created
+// additionally takes PermOrdersReadAll, as listing it with scope=business does.
+// Returns false after writing an error response.
+func loadAuthorizedOrder(ctx context.Context, db *sql.DB, w http.ResponseWriter, orderID string, u *auth.User, perm businessuser.Permission) (Order, bool) {
+	logger := slog.Default().With(
+		slog.String("component", "orders"),
+		slog.String("op", "loadAuthorizedOrder"),
+		slog.String("order_id", orderID),
+	)
+
+	if _, err := uuid.Parse(orderID); err != nil {
+		httpx.WriteErr(w, http.StatusNotFound, "order not found")
+		return Order{}, false
+	}
+
+	var (
+		creatorName *string
+		own         bool
+	)
+	ord, err := scanOrder(db.QueryRowContext(ctx, `
+		SELECT `+orderColumns+`, `+creatorNameColumn+`,
+			COALESCE(o.created_by = (SELECT id FROM "user" WHERE firebase_id = $2), false)
+		FROM "order" o
+		WHERE o.id = $1`,
+		orderID, u.UID,
+	), &creatorName, &own)
+	ord.CreatedByName = creatorName
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusNotFound, "order not found")
+		return Order{}, false
+	} else if err != nil {
+		logger.Error("query order failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Order{}, false
+	}
+
+	role, member, err := businessuser.LookupRole(ctx, db, ord.BusinessID, u)
+	if err != nil {
+		logger.Error("membership check failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Order{}, false
+	}
+	if !member {
+		httpx.WriteErr(w, http.StatusNotFound, "order not found")
+		return Order{}, false
+	}
+	if !businessuser.Allows(u, role, perm) {
+		businessuser.WriteMissingPermission(w, perm)
+		return Order{}, false
+	}
+	if perm == businessuser.PermOrdersRead && !own && !businessuser.Allows(u, role, businessuser.PermOrdersReadAll) {
+		businessuser.WriteMissingPermission(w, businessuser.PermOrdersReadAll)
+		return Order{}, false
+	}
+	return ord, true
 }
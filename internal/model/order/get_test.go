@@ -0,0 +1,42 @@
+package order
+
+import (
+	"net/http"
+	"testing"
+)
+
+// TestReadOrderOfAnotherMember checks that reading an order by ID follows the listing rule: the
+// caller created the order or may read every order of the business.
+func TestReadOrderOfAnotherMember(t *testing.T) {
+	env := newTestEnv(t)
+	biz := env.newBusiness(t, "BZD")
+	creatorID, creator := env.newMember(t, biz, "cashier")
+	_, otherCashier := env.newMember(t, biz, "cashier")
+	_, viewer := env.newMember(t, biz, "viewer")
+	_, admin := env.newMember(t, biz, "admin")
+	ord := env.newOrder(t, biz, creatorID, 1000, "BZD")
+
+	otherBiz := env.newBusiness(t, "BZD")
+	_, outsider := env.newMember(t, otherBiz, "owner")
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"creator", creator, http.StatusOK},
+		{"viewer", viewer, http.StatusOK},
+		{"admin", admin, http.StatusOK},
+		{"another cashier", otherCashier, http.StatusForbidden},
+		{"member of another business", outsider, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, path := range []string{"/" + ord.ID, "/" + ord.ID + "/payments", "/" + ord.ID + "/refunds"} {
+				if rec := env.do(t, tt.token, http.MethodGet, path, ""); rec.Code != tt.want {
+					t.Errorf("GET %s: status = %d, want %d: %s", path, rec.Code, tt.want, rec.Body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/httpx"
+	"backend/internal/payment"
+)
+
+// Outcomes of an inbound provider event, stored in provider_event.status.
+const (
+	eventProcessed = "processed"
+	eventIgnored   = "ignored"
+	eventReview    = "review" // could not be applied automatically; waits in the review queue at /api/provider-events
+	eventDuplicate = "duplicate"
+)
+
+// localEventPrefix marks review entries raised by this service rather than received from the
+// provider, e.g. a payment captured for an order that was cancelled meanwhile.
+const localEventPrefix = "local:"
+
+// ProviderEventResponse acknowledges an inbound provider webhook.
+// Status is processed, ignored, review or duplicate.
+type ProviderEventResponse struct {
+	Status string `json:"status"`
+}
+
+// ProviderWebhookRoutes serves the public endpoints providers call with asynchronous results.
+// It must be mounted without authentication; requests are authenticated by their signature.
+func ProviderWebhookRoutes(db *sql.DB, payments *payment.Registry) http.Handler {
+	r := chi.NewRouter()
+	r.Post("/{provider}", func(w http.ResponseWriter, r *http.Request) { receiveProviderEvent(db, payments, w, r) })
+	return r
+}
+
+// receiveProviderEvent handles POST /webhooks/{provider}
+//
+// @Summary      Receive a payment provider webhook
+// @Description  Verifies the provider's signature, stores the event once per provider event ID and applies it to the payment and order. Events that cannot be matched or applied are acknowledged and put in the review queue, which platform admins work through /api/provider-events.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        provider  path      string  true  "Provider name, e.g. simulator"
+// @Success      200       {object}  ProviderEventResponse
+// @Failure      400       {object}  ErrorResponse
+// @Failure      401       {object}  ErrorResponse  "Invalid signature"
+// @Failure      404       {object}  ErrorResponse  "Unknown provider"
+// @Router       /webhooks/{provider} [post]
+func receiveProviderEvent(db *sql.DB, payments *payment.Registry, w http.ResponseWriter, r *http.Request) {
+
+	name := chi.URLParam(r, "provider")
+	provider, ok := payments.Get(name)
+	if !ok {
+		httpx.WriteErr(w, http.StatusNotFound, "unknown payment provider")
+		return
+	}
+
+	defer r.Body.Close()
+	ev, err := provider.ParseWebhook(r)
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		httpx.WriteErr(w, http.StatusUnauthorized, "invalid signature")
+		return
+	} else if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, "invalid webhook payload")
+		return
+	}
+
+	logger := slog.Default().With(
+		slog.String("component", "payments"),
+		slog.String("op", "receiveProviderEvent"),
+		slog.String("provider", name),
+		slog.String("event_id", ev.ID),
+		slog.String("type", string(ev.Type)),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("begin tx failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var eventRowID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO provider_event (provider, event_id, type, payment_ref, refund_ref, payload, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (provider, event_id) DO NOTHING
+		RETURNING id`,
+		name, ev.ID, ev.Type, nullIfEmpty(ev.PaymentRef), nullIfEmpty(ev.RefundRef), ev.Raw, eventProcessed,
+	).Scan(&eventRowID)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Info("duplicate provider event ignored")
+		httpx.WriteJSON(w, http.StatusOK, ProviderEventResponse{Status: eventDuplicate})
+		return
+	} else if err != nil {
+		logger.Error("store provider event failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	// Database errors abort the transaction and return 500 so the provider retries;
+	// everything else is recorded on the event and acknowledged.
+	outcome, orderID, err := applyProviderEvent(ctx, tx, name, ev)
+	if err != nil {
+		logger.Error("apply provider event failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE provider_event SET status = $2, review_reason = $3, order_id = $4
+		WHERE id = $1`,
+		eventRowID, outcome.status, nullIfEmpty(outcome.reason), nullIfEmpty(orderID),
+	); err != nil {
+		logger.Error("update provider event failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("commit failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	if outcome.status == eventReview {
+		logger.Warn("provider event needs review", slog.String("reason", outcome.reason), slog.String("order_id", orderID))
+	}
+	httpx.WriteJSON(w, http.StatusOK, ProviderEventResponse{Status: outcome.status})
+}
+
+// eventOutcome is how an inbound event was handled; reason explains review outcomes.
+type eventOutcome struct {
+	status string
+	reason string
+}
+
+func review(format string, args ...any) eventOutcome {
+	return eventOutcome{status: eventReview, reason: fmt.Sprintf(format, args...)}
+}
+
+// queueReview puts a provider result that was recorded but could not be applied to its order in
+// the review queue, next to the provider's own events. key is the local payment or refund ID, so
+// each is queued once; orderID may be empty.
+func queueReview(ctx context.Context, tx *sql.Tx, provider string, typ payment.EventType, key, paymentRef, refundRef, orderID, reason string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO provider_event (provider, event_id, type, payment_ref, refund_ref, payload, status, review_reason, order_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (provider, event_id) DO NOTHING`,
+		provider, localEventPrefix+key, typ, nullIfEmpty(paymentRef), nullIfEmpty(refundRef), []byte{}, eventReview, reason, nullIfEmpty(orderID),
+	)
+	return err
+}
+
+// applyProviderEvent updates the payment or refund and the order an event refers to. It returns
+// the order ID when the payment or refund was found. A non-nil error means the transaction must be
+// rolled back.
+func applyProviderEvent(ctx context.Context, tx *sql.Tx, provider string, ev payment.Event) (eventOutcome, string, error) {
+	switch ev.Type {
+	case payment.EventPaymentSucceeded, payment.EventPaymentFailed:
+	case payment.EventRefundSucceeded, payment.EventRefundFailed:
+		return applyRefundEvent(ctx, tx, provider, ev)
+	default:
+		return eventOutcome{status: eventIgnored, reason: "unsupported event type"}, "", nil
+	}
+
+	pay, err := scanPayment(tx.QueryRowContext(ctx,
+		`SELECT `+paymentColumns+` FROM payment WHERE provider = $1 AND provider_ref = $2 FOR UPDATE`,
+		provider, ev.PaymentRef,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return review("no payment with provider reference %q", ev.PaymentRef), "", nil
+	} else if err != nil {
+		return eventOutcome{}, "", err
+	}
+	if ev.Amount.Minor != pay.AmountMinor || ev.Amount.Currency != pay.Currency {
+		return review("event amount %s %s does not match payment amount %s %s", ev.Amount, ev.Amount.Currency, pay.Amount, pay.Currency), pay.OrderID, nil
+	}
+
+	if ev.Type == payment.EventPaymentFailed {
+		switch pay.Status {
+		case PaymentFailed:
+			return eventOutcome{status: eventProcessed}, pay.OrderID, nil
+		case PaymentSucceeded:
+			return review("payment %s already succeeded but the provider reports a failure", pay.ID), pay.OrderID, nil
+		}
+		reason := ev.FailureReason
+		if reason == "" {
+			reason = "declined"
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE payment SET status = $2, failure_reason = $3, updated_at = now()
+			WHERE id = $1`,
+			pay.ID, PaymentFailed, reason,
+		)
+		return eventOutcome{status: eventProcessed}, pay.OrderID, err
+	}
+
+	switch pay.Status {
+	case PaymentSucceeded:
+		return eventOutcome{status: eventProcessed}, pay.OrderID, nil
+	case PaymentFailed:
+		return review("payment %s was marked failed but the provider reports success", pay.ID), pay.OrderID, nil
+	}
+	pay, err = scanPayment(tx.QueryRowContext(ctx, `
+		UPDATE payment SET status = $2, fee_minor = $3, failure_reason = NULL, captured_at = now(), updated_at = now()
+		WHERE id = $1
+		RETURNING `+paymentColumns,
+		pay.ID, PaymentSucceeded, ev.Fee.Minor,
+	))
+	if err != nil {
+		return eventOutcome{}, "", err
+	}
+	if err := postPayment(ctx, tx, pay); err != nil {
+		return eventOutcome{}, "", err
+	}
+
+	_, err = transition(ctx, tx, pay.OrderID, StatusPaid, Actor{Kind: ActorProvider}, "payment "+pay.ID)
+	var te *TransitionError
+	switch {
+	case err == nil:
+		return eventOutcome{status: eventProcessed}, pay.OrderID, nil
+	case errors.As(err, &te):
+		// The customer paid, but the order moved on meanwhile (e.g. it was cancelled).
+		return review("payment %s succeeded but %s", pay.ID, te.Error()), pay.OrderID, nil
+	case errors.Is(err, errOrderNotFound):
+		return review("payment %s succeeded but its order no longer exists", pay.ID), "", nil
+	default:
+		return eventOutcome{}, "", err
+	}
+}
+
+// applyRefundEvent settles a pending refund from a refund.succeeded or refund.failed event.
+func applyRefundEvent(ctx context.Context, tx *sql.Tx, provider string, ev payment.Event) (eventOutcome, string, error) {
+	rf, err := scanRefund(tx.QueryRowContext(ctx,
+		`SELECT `+refundColumns+` FROM refund WHERE provider = $1 AND provider_ref = $2 FOR UPDATE`,
+		provider, ev.RefundRef,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return review("no refund with provider reference %q", ev.RefundRef), "", nil
+	} else if err != nil {
+		return eventOutcome{}, "", err
+	}
+	if ev.Amount.Minor != rf.AmountMinor || ev.Amount.Currency != rf.Currency {
+		return review("event amount %s %s does not match refund amount %s %s", ev.Amount, ev.Amount.Currency, rf.Amount, rf.Currency), rf.OrderID, nil
+	}
+
+	if ev.Type == payment.EventRefundFailed {
+		switch rf.Status {
+		case RefundFailed:
+			return eventOutcome{status: eventProcessed}, rf.OrderID, nil
+		case RefundSucceeded:
+			return review("refund %s already succeeded but the provider reports a failure", rf.ID), rf.OrderID, nil
+		}
+		reason := ev.FailureReason
+		if reason == "" {
+			reason = "refund_failed"
+		}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE refund SET status = $2, failure_reason = $3, updated_at = now()
+			WHERE id = $1`,
+			rf.ID, RefundFailed, reason,
+		)
+		return eventOutcome{status: eventProcessed}, rf.OrderID, err
+	}
+
+	switch rf.Status {
+	case RefundSucceeded:
+		return eventOutcome{status: eventProcessed}, rf.OrderID, nil
+	case RefundFailed:
+		return review("refund %s was marked failed but the provider reports success", rf.ID), rf.OrderID, nil
+	}
+
+	_, err = completeRefund(ctx, tx, rf.ID, ev.RefundRef, Actor{Kind: ActorProvider})
+	var te *TransitionError
+	switch {
+	case err == nil:
+		return eventOutcome{status: eventProcessed}, rf.OrderID, nil
+	case errors.As(err, &te):
+		return review("refund %s succeeded but %s", rf.ID, te.Error()), rf.OrderID, nil
+	default:
+		return eventOutcome{}, "", err
+	}
+}
@@ -0,0 +1,62 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+
+	"backend/internal/model/ledger"
+)
+
+// postPayment records a captured payment in the ledger: the gross amount is earned as revenue
+// and held as pending funds, and the provider's fee is deducted from them.
+func postPayment(ctx context.Context, tx *sql.Tx, pay Payment) error {
+	if err := ledger.Post(ctx, tx, ledger.Transaction{
+		BusinessID:  pay.BusinessID,
+		Kind:        ledger.KindPayment,
+		Currency:    pay.Currency,
+		OrderID:     pay.OrderID,
+		PaymentID:   pay.ID,
+		Description: "payment " + pay.ID,
+		Entries:     ledger.Transfer(ledger.AccountPending, ledger.AccountRevenue, pay.AmountMinor),
+	}); err != nil {
+		return err
+	}
+	if pay.FeeMinor == 0 {
+		return nil
+	}
+	return ledger.Post(ctx, tx, ledger.Transaction{
+		BusinessID:  pay.BusinessID,
+		Kind:        ledger.KindFee,
+		Currency:    pay.Currency,
+		OrderID:     pay.OrderID,
+		PaymentID:   pay.ID,
+		Description: "fee for payment " + pay.ID,
+		Entries:     ledger.Transfer(ledger.AccountFees, ledger.AccountPending, pay.FeeMinor),
+	})
+}
+
+// postRefund records a succeeded refund in the ledger. It is paid out of the pending funds,
+// or out of the available funds once the payment has settled.
+func postRefund(ctx context.Context, tx *sql.Tx, rf Refund) error {
+	// Locking the payment orders this against its settlement.
+	var settled bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT settled_at IS NOT NULL FROM payment WHERE id = $1 FOR UPDATE`, rf.PaymentID,
+	).Scan(&settled); err != nil {
+		return err
+	}
+	from := ledger.AccountPending
+	if settled {
+		from = ledger.AccountAvailable
+	}
+	return ledger.Post(ctx, tx, ledger.Transaction{
+		BusinessID:  rf.BusinessID,
+		Kind:        ledger.KindRefund,
+		Currency:    rf.Currency,
+		OrderID:     rf.OrderID,
+		PaymentID:   rf.PaymentID,
+		RefundID:    rf.ID,
+		Description: "refund " + rf.ID,
+		Entries:     ledger.Transfer(ledger.AccountRefunds, from, rf.AmountMinor),
+	})
+}
@@ -3,14 +3,22 @@ package order
 import (
 	"database/sql"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"backend/internal/payment"
 )
 
 // Routes aggregates all order submodule routes (create, get, etc.)
-func Routes(db *sql.DB) http.Handler {
+// publicURL is the base URL of emailed payment links.
+func Routes(db *sql.DB, payments *payment.Registry, linkTTL time.Duration, publicURL string) http.Handler {
 	r := chi.NewRouter()
-	attachCreateRoutes(r, db)
+	attachCreateRoutes(r, db, linkTTL)
 	attachGetRoutes(r, db)
+	attachTransitionRoutes(r, db)
+	attachPaymentRoutes(r, db, payments)
+	attachPaymentLinkRoutes(r, db, linkTTL, publicURL)
+	attachRefundRoutes(r, db, payments)
 	return r
 }
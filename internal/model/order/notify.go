@@ -0,0 +1,151 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+	"backend/internal/model/notification"
+)
+
+// notifyPaid queues the customer's receipt, when the order has a customer email, and an alert to
+// every owner and admin of the business with a known email.
+func notifyPaid(ctx context.Context, tx *sql.Tx, ord Order) error {
+	var businessName string
+	if err := tx.QueryRowContext(ctx, `SELECT name FROM business WHERE id = $1`, ord.BusinessID).Scan(&businessName); err != nil {
+		return err
+	}
+	data := notification.OrderData{
+		BusinessName:  businessName,
+		OrderID:       ord.ID,
+		Description:   derefString(ord.Description),
+		Amount:        ord.Amount,
+		Currency:      ord.Currency,
+		CustomerEmail: derefString(ord.CustomerEmail),
+		PaidAt:        ord.UpdatedAt,
+	}
+
+	if ord.CustomerEmail != nil {
+		if err := notification.Enqueue(ctx, tx, ord.BusinessID, *ord.CustomerEmail, notification.TemplateReceipt, data); err != nil {
+			return err
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT u.email
+		FROM business_user bu
+		JOIN "user" u ON u.id = bu.user_id
+		WHERE bu.business_id = $1 AND bu.role IN ($2, $3) AND u.email IS NOT NULL`,
+		ord.BusinessID, businessuser.RoleOwner, businessuser.RoleAdmin,
+	)
+	if err != nil {
+		return err
+	}
+	var recipients []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			rows.Close()
+			return err
+		}
+		recipients = append(recipients, email)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, to := range recipients {
+		if err := notification.Enqueue(ctx, tx, ord.BusinessID, to, notification.TemplateOrderPaid, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendPaymentLink handles POST /api/orders/{orderID}/payment-link/send
+//
+// @Summary      Email an order's payment link
+// @Description  Queues an email with the order's payment link to the order's customer. The order must be pending, have an unexpired link and a customer with an email.
+// @Tags         orders
+// @Produce      json
+// @Param        orderID  path      string  true  "Order ID"
+// @Success      202      {object}  Order
+// @Failure      403      {object}  businessuser.MissingPermissionResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse  "Order is not pending, its link expired or it has no customer email"
+// @Router       /api/orders/{orderID}/payment-link/send [post]
+func sendPaymentLink(db *sql.DB, publicURL string, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	orderID := chi.URLParam(r, "orderID")
+	logger := slog.Default().With(
+		slog.String("component", "orders"),
+		slog.String("op", "sendPaymentLink"),
+		slog.String("order_id", orderID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	ord, ok := loadAuthorizedOrder(ctx, db, w, orderID, u, businessuser.PermOrdersUpdate)
+	if !ok {
+		return
+	}
+	switch {
+	case ord.Status != StatusPending:
+		httpx.WriteErr(w, http.StatusConflict, "only pending orders have a payment link")
+		return
+	case !time.Now().Before(ord.PaymentLinkExpiresAt):
+		httpx.WriteErr(w, http.StatusConflict, "the payment link has expired; renew it first")
+		return
+	case ord.CustomerEmail == nil:
+		httpx.WriteErr(w, http.StatusConflict, "order has no customer email")
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("begin tx failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var businessName string
+	if err := tx.QueryRowContext(ctx, `SELECT name FROM business WHERE id = $1`, ord.BusinessID).Scan(&businessName); err != nil {
+		logger.Error("query business failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	if err := notification.Enqueue(ctx, tx, ord.BusinessID, *ord.CustomerEmail, notification.TemplatePaymentLink, notification.PaymentLinkData{
+		BusinessName: businessName,
+		Description:  derefString(ord.Description),
+		Amount:       ord.Amount,
+		Currency:     ord.Currency,
+		URL:          publicURL + "/pay/" + ord.PaymentLinkToken,
+		ExpiresAt:    ord.PaymentLinkExpiresAt,
+	}); err != nil {
+		logger.Error("enqueue payment link email failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		logger.Error("commit failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusAccepted, ord)
+}
@@ -0,0 +1,255 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"backend/internal/httpx"
+	"backend/internal/money"
+	"backend/internal/payment"
+)
+
+// recordTimeout bounds the writes that record a provider's answer. They run detached from the
+// request so an expired request deadline cannot leave a payment or refund pending.
+const recordTimeout = 5 * time.Second
+
+// PaymentStatus is the local state of a payment attempt.
+type PaymentStatus string
+
+const (
+	PaymentPending    PaymentStatus = "pending"    // created locally, provider not yet called
+	PaymentProcessing PaymentStatus = "processing" // the provider reports the result later by webhook
+	PaymentSucceeded  PaymentStatus = "succeeded"
+	PaymentFailed     PaymentStatus = "failed"
+)
+
+// Payment is one attempt to collect an order's amount through a provider.
+type Payment struct {
+	ID            string        `json:"id"`
+	OrderID       string        `json:"order_id"`
+	BusinessID    string        `json:"business_id"`
+	Provider      string        `json:"provider"`
+	ProviderRef   *string       `json:"provider_ref,omitempty"`
+	PaymentMethod *string       `json:"payment_method,omitempty"`
+	Status        PaymentStatus `json:"status"`
+	Amount        string        `json:"amount"`
+	AmountMinor   int64         `json:"amount_minor"`
+	Currency      string        `json:"currency"`
+	Fee           string        `json:"fee"`
+	FeeMinor      int64         `json:"fee_minor"`
+	FailureReason *string       `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	CapturedAt    *time.Time    `json:"captured_at,omitempty"`
+	SettledAt     *time.Time    `json:"settled_at,omitempty"` // when the net amount became available for payout
+}
+
+// paymentColumns is the column list scanned by scanPayment.
+const paymentColumns = `id, order_id, business_id, provider, provider_ref, payment_method, status, amount_minor, currency, fee_minor, failure_reason, created_at, updated_at, captured_at, settled_at`
+
+func scanPayment(row rowScanner) (Payment, error) {
+	var p Payment
+	err := row.Scan(&p.ID, &p.OrderID, &p.BusinessID, &p.Provider, &p.ProviderRef, &p.PaymentMethod, &p.Status,
+		&p.AmountMinor, &p.Currency, &p.FeeMinor, &p.FailureReason, &p.CreatedAt, &p.UpdatedAt, &p.CapturedAt, &p.SettledAt)
+	p.Amount = money.FromMinor(p.AmountMinor, p.Currency).String()
+	p.Fee = money.FromMinor(p.FeeMinor, p.Currency).String()
+	return p, err
+}
+
+// collectPayment charges the full amount of a pending order through provider and marks the order paid
+// once the provider captures it. createdBy is the internal user ID of the initiator, or empty for
+// payments made by customers through a payment link.
+//
+// On success the returned payment is either succeeded or processing; processing payments are
+// settled later by the provider's webhook. Errors are written to w:
+// 409 when the order is not pending or another payment is in flight, 402 when the payment
+// method is declined and 502 when the provider fails.
+func collectPayment(ctx context.Context, db *sql.DB, w http.ResponseWriter, provider payment.Provider, ord Order, method, createdBy string) (Payment, bool) {
+	logger := slog.Default().With(
+		slog.String("component", "payments"),
+		slog.String("op", "collectPayment"),
+		slog.String("order_id", ord.ID),
+		slog.String("provider", provider.Name()),
+	)
+
+	if ord.Status != StatusPending {
+		httpx.WriteErr(w, http.StatusConflict, "only pending orders can be paid, order is "+string(ord.Status))
+		return Payment{}, false
+	}
+
+	pay, err := scanPayment(db.QueryRowContext(ctx, `
+		INSERT INTO payment (order_id, business_id, provider, payment_method, status, amount_minor, currency, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+paymentColumns,
+		ord.ID, ord.BusinessID, provider.Name(), nullIfEmpty(method), PaymentPending, ord.AmountMinor, ord.Currency, nullIfEmpty(createdBy),
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			httpx.WriteErr(w, http.StatusConflict, "order already has a payment in progress or completed")
+			return Payment{}, false
+		}
+		logger.Error("insert payment failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Payment{}, false
+	}
+	logger = logger.With(slog.String("payment_id", pay.ID))
+
+	intent, err := provider.CreateIntent(ctx, payment.IntentRequest{
+		OrderID:        ord.ID,
+		Amount:         money.FromMinor(ord.AmountMinor, ord.Currency),
+		PaymentMethod:  method,
+		Description:    derefString(ord.Description),
+		IdempotencyKey: pay.ID,
+	})
+	if err != nil {
+		writeProviderErr(ctx, db, w, logger, pay.ID, err)
+		return Payment{}, false
+	}
+
+	if intent.Status == payment.StatusProcessing {
+		// Without the reference the provider's webhook cannot find the payment, so it is
+		// recorded even if the request deadline has passed.
+		recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+		defer cancel()
+		pay, err = scanPayment(db.QueryRowContext(recordCtx, `
+			UPDATE payment SET provider_ref = $2, status = $3, updated_at = now()
+			WHERE id = $1
+			RETURNING `+paymentColumns,
+			pay.ID, intent.Ref, PaymentProcessing,
+		))
+		if err != nil {
+			logger.Error("update processing payment failed", slog.String("provider_ref", intent.Ref), slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return Payment{}, false
+		}
+		return pay, true
+	}
+
+	capture, err := provider.Capture(ctx, intent.Ref)
+	if err == nil && capture.Status != payment.StatusSucceeded {
+		err = &payment.DeclineError{Reason: "capture_" + string(capture.Status)}
+	}
+	if err != nil {
+		writeProviderErr(ctx, db, w, logger, pay.ID, err)
+		return Payment{}, false
+	}
+
+	// The funds are taken. Record that without the request deadline, which the provider calls may
+	// have used up.
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	pay, err = recordCapture(recordCtx, db, pay.ID, intent.Ref, capture.Fee.Minor)
+	var te *TransitionError
+	switch {
+	case err == nil:
+		return pay, true
+	case errors.As(err, &te), errors.Is(err, errOrderNotFound):
+		logger.Warn("payment captured for an order that is no longer pending, queued for review", slog.Any("err", err))
+		httpx.WriteErr(w, http.StatusConflict, "payment captured, but "+err.Error()+"; it was queued for review")
+		return Payment{}, false
+	default:
+		// RunReconciler marks the still pending payment abandoned and queues it for review.
+		logger.Error("record captured payment failed", slog.String("provider_ref", intent.Ref), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Payment{}, false
+	}
+}
+
+// recordCapture marks a payment succeeded, posts it to the ledger and marks its order paid in one
+// transaction. When the order moved on meanwhile (e.g. it was cancelled) or no longer exists, the
+// payment and its ledger entries are recorded all the same, the payment is put in the review queue
+// and the *TransitionError or errOrderNotFound is returned.
+func recordCapture(ctx context.Context, db *sql.DB, paymentID, providerRef string, feeMinor int64) (Payment, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Payment{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	pay, err := scanPayment(tx.QueryRowContext(ctx, `
+		UPDATE payment SET provider_ref = $2, status = $3, fee_minor = $4, captured_at = now(), updated_at = now()
+		WHERE id = $1
+		RETURNING `+paymentColumns,
+		paymentID, providerRef, PaymentSucceeded, feeMinor,
+	))
+	if err != nil {
+		return Payment{}, err
+	}
+	if err := postPayment(ctx, tx, pay); err != nil {
+		return Payment{}, err
+	}
+
+	_, err = transition(ctx, tx, pay.OrderID, StatusPaid, Actor{Kind: ActorProvider}, "payment "+pay.ID)
+	var te *TransitionError
+	switch {
+	case err == nil:
+	case errors.As(err, &te):
+		if qerr := queueReview(ctx, tx, pay.Provider, payment.EventPaymentSucceeded, pay.ID, providerRef, "", pay.OrderID,
+			fmt.Sprintf("payment %s succeeded but %s", pay.ID, te.Error())); qerr != nil {
+			return Payment{}, qerr
+		}
+	case errors.Is(err, errOrderNotFound):
+		if qerr := queueReview(ctx, tx, pay.Provider, payment.EventPaymentSucceeded, pay.ID, providerRef, "", "",
+			fmt.Sprintf("payment %s succeeded but its order no longer exists", pay.ID)); qerr != nil {
+			return Payment{}, qerr
+		}
+	default:
+		return Payment{}, err
+	}
+	if cerr := tx.Commit(); cerr != nil {
+		return Payment{}, cerr
+	}
+	return pay, err
+}
+
+// writeProviderErr marks the payment failed and maps the provider error to 402 or 502.
+// The update does not depend on the request deadline, which a slow provider may have used up.
+func writeProviderErr(ctx context.Context, db *sql.DB, w http.ResponseWriter, logger *slog.Logger, paymentID string, err error) {
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+	if _, uerr := db.ExecContext(recordCtx, `
+		UPDATE payment SET status = $2, failure_reason = $3, updated_at = now()
+		WHERE id = $1`,
+		paymentID, PaymentFailed, failureReason(err),
+	); uerr != nil {
+		// RunReconciler marks the still pending payment abandoned.
+		logger.Error("mark payment failed failed", slog.Any("err", uerr))
+	}
+	writeProviderResponse(w, logger, err)
+}
+
+// failureReason is the reason stored for a failed provider call: the decline reason, or provider_error.
+func failureReason(err error) string {
+	var decline *payment.DeclineError
+	if errors.As(err, &decline) {
+		return decline.Reason
+	}
+	return "provider_error"
+}
+
+// writeProviderResponse writes 402 for declines and 502 for any other provider error.
+func writeProviderResponse(w http.ResponseWriter, logger *slog.Logger, err error) {
+	var decline *payment.DeclineError
+	if errors.As(err, &decline) {
+		httpx.WriteErr(w, http.StatusPaymentRequired, decline.Error())
+		return
+	}
+	logger.Error("payment provider error", slog.Any("err", err))
+	httpx.WriteErr(w, http.StatusBadGateway, "payment provider unavailable")
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
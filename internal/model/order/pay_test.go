@@ -0,0 +1,178 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"backend/internal/dbtest"
+	"backend/internal/money"
+	"backend/internal/payment"
+	"backend/internal/payment/simulator"
+)
+
+// balance returns the balance of a business's ledger account.
+func (e *testEnv) balance(t *testing.T, businessID, code string) int64 {
+	t.Helper()
+	var n int64
+	dbtest.QueryValue(t, e.db, &n, `
+		SELECT COALESCE(sum(e.amount_minor), 0)
+		FROM ledger_entry e
+		JOIN ledger_account a ON a.id = e.account_id
+		WHERE a.business_id = $1 AND a.code = $2`,
+		businessID, code)
+	return n
+}
+
+// reviewCount returns how many events wait in the review queue.
+func (e *testEnv) reviewCount(t *testing.T) int {
+	t.Helper()
+	var n int
+	dbtest.QueryValue(t, e.db, &n, `SELECT count(*) FROM provider_event WHERE status = $1`, eventReview)
+	return n
+}
+
+// TestPayCaptureRefund pays an order through the simulator and refunds it in two parts,
+// checking the order, its history and the ledger after every step.
+func TestPayCaptureRefund(t *testing.T) {
+	env := newTestEnv(t)
+	biz := env.newBusiness(t, "BZD")
+	ownerID, owner := env.newMember(t, biz, "owner")
+	ord := env.newOrder(t, biz, ownerID, 2500, "BZD")
+
+	rec := env.do(t, owner, http.MethodPost, "/"+ord.ID+"/payments", `{"payment_method":"sim_succeed"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("pay: status = %d: %s", rec.Code, rec.Body)
+	}
+	pay := decode[Payment](t, rec)
+	const fee = 2500*290/10000 + 30
+	if pay.Status != PaymentSucceeded || pay.FeeMinor != fee || pay.ProviderRef == nil {
+		t.Fatalf("payment = %+v", pay)
+	}
+	if got := env.orderStatus(t, ord.ID); got != StatusPaid {
+		t.Fatalf("order status after payment = %s", got)
+	}
+	wantBalances := map[string]int64{"pending": 2500 - fee, "revenue": -2500, "fees": fee, "refunds": 0}
+	for code, want := range wantBalances {
+		if got := env.balance(t, biz, code); got != want {
+			t.Errorf("%s balance after payment = %d, want %d", code, got, want)
+		}
+	}
+
+	if rec := env.do(t, owner, http.MethodPost, "/"+ord.ID+"/payments", `{}`); rec.Code != http.StatusConflict {
+		t.Fatalf("second payment: status = %d, want 409", rec.Code)
+	}
+
+	rec = env.do(t, owner, http.MethodPost, "/"+ord.ID+"/refunds", `{"amount":10.00,"reason":"returned one item"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("partial refund: status = %d: %s", rec.Code, rec.Body)
+	}
+	if rf := decode[Refund](t, rec); rf.Status != RefundSucceeded || rf.AmountMinor != 1000 {
+		t.Fatalf("partial refund = %+v", rf)
+	}
+	if got := env.orderStatus(t, ord.ID); got != StatusPartiallyRefunded {
+		t.Fatalf("order status after partial refund = %s", got)
+	}
+
+	if rec := env.do(t, owner, http.MethodPost, "/"+ord.ID+"/refunds", `{"amount":15.01,"reason":"too much"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("refund beyond the remainder: status = %d, want 400", rec.Code)
+	}
+
+	rec = env.do(t, owner, http.MethodPost, "/"+ord.ID+"/refunds", `{"reason":"returned the rest"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("final refund: status = %d: %s", rec.Code, rec.Body)
+	}
+	if rf := decode[Refund](t, rec); rf.AmountMinor != 1500 {
+		t.Fatalf("final refund amount = %d, want 1500", rf.AmountMinor)
+	}
+	if got := env.orderStatus(t, ord.ID); got != StatusRefunded {
+		t.Fatalf("order status after final refund = %s", got)
+	}
+	if rec := env.do(t, owner, http.MethodPost, "/"+ord.ID+"/refunds", `{"reason":"again"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("refund of a refunded order: status = %d, want 409", rec.Code)
+	}
+
+	wantBalances = map[string]int64{"pending": 2500 - fee - 2500, "revenue": -2500, "fees": fee, "refunds": 2500}
+	for code, want := range wantBalances {
+		if got := env.balance(t, biz, code); got != want {
+			t.Errorf("%s balance after refunds = %d, want %d", code, got, want)
+		}
+	}
+	// created, paid, partially_refunded, refunded
+	if got := env.historyCount(t, ord.ID); got != 4 {
+		t.Errorf("history rows = %d, want 4", got)
+	}
+	if got := env.reviewCount(t); got != 0 {
+		t.Errorf("review queue has %d events, want 0", got)
+	}
+}
+
+func TestPayDeclined(t *testing.T) {
+	env := newTestEnv(t)
+	biz := env.newBusiness(t, "BZD")
+	ownerID, owner := env.newMember(t, biz, "owner")
+	ord := env.newOrder(t, biz, ownerID, 2500, "BZD")
+
+	if rec := env.do(t, owner, http.MethodPost, "/"+ord.ID+"/payments", `{"payment_method":"sim_decline"}`); rec.Code != http.StatusPaymentRequired {
+		t.Fatalf("status = %d, want 402: %s", rec.Code, rec.Body)
+	}
+	var status PaymentStatus
+	dbtest.QueryValue(t, env.db, &status, `SELECT status FROM payment WHERE order_id = $1`, ord.ID)
+	if status != PaymentFailed {
+		t.Fatalf("payment status = %s, want failed", status)
+	}
+	if got := env.orderStatus(t, ord.ID); got != StatusPending {
+		t.Fatalf("order status = %s, want pending", got)
+	}
+	if got := env.balance(t, biz, "pending"); got != 0 {
+		t.Fatalf("pending balance = %d, want 0", got)
+	}
+
+	// A failed attempt does not block the next one.
+	if rec := env.do(t, owner, http.MethodPost, "/"+ord.ID+"/payments", `{"payment_method":"sim_succeed"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("retry: status = %d: %s", rec.Code, rec.Body)
+	}
+}
+
+// TestRecordCaptureForCancelledOrder captures a payment whose order was cancelled meanwhile:
+// the payment and its ledger entries are kept and the payment waits in the review queue.
+func TestRecordCaptureForCancelledOrder(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	biz := env.newBusiness(t, "BZD")
+	ownerID, _ := env.newMember(t, biz, "owner")
+	ord := env.newOrder(t, biz, ownerID, 2500, "BZD")
+
+	var paymentID string
+	dbtest.QueryValue(t, env.db, &paymentID, `
+		INSERT INTO payment (order_id, business_id, provider, status, amount_minor, currency)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		ord.ID, biz, simulator.Name, PaymentPending, ord.AmountMinor, ord.Currency)
+	in, err := env.sim.CreateIntent(ctx, payment.IntentRequest{OrderID: ord.ID, Amount: money.FromMinor(2500, "BZD"), IdempotencyKey: paymentID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.setStatus(t, ord.ID, StatusCancelled)
+
+	pay, err := recordCapture(ctx, env.db, paymentID, in.Ref, 102)
+	var te *TransitionError
+	if !errors.As(err, &te) {
+		t.Fatalf("err = %v, want a *TransitionError", err)
+	}
+	if pay.Status != PaymentSucceeded {
+		t.Fatalf("payment status = %s, want succeeded", pay.Status)
+	}
+	if got := env.balance(t, biz, "pending"); got != 2500-102 {
+		t.Fatalf("pending balance = %d, want %d", got, 2500-102)
+	}
+	if got := env.orderStatus(t, ord.ID); got != StatusCancelled {
+		t.Fatalf("order status = %s, want cancelled", got)
+	}
+	var eventID string
+	dbtest.QueryValue(t, env.db, &eventID, `SELECT event_id FROM provider_event WHERE status = $1 AND order_id = $2`, eventReview, ord.ID)
+	if eventID != localEventPrefix+paymentID {
+		t.Fatalf("review event ID = %s, want %s", eventID, localEventPrefix+paymentID)
+	}
+}
@@ -0,0 +1,280 @@
+package order
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+	"backend/internal/payment"
+)
+
+// paymentLinkPrefix marks payment link tokens so they are recognizable in logs and support requests.
+const paymentLinkPrefix = "pl_"
+
+// Payment link states reported when a link can no longer be used.
+const (
+	LinkStateExpired     = "expired"
+	LinkStatePaid        = "paid"
+	LinkStateCancelled   = "cancelled"
+	LinkStateUnavailable = "unavailable"
+)
+
+// PaymentLinkErrorResponse explains why a payment link cannot be used. State is one of
+// expired, paid, cancelled or unavailable (the business was archived).
+type PaymentLinkErrorResponse struct {
+	Error string `json:"error"`
+	State string `json:"state"`
+}
+
+// PaymentLink is the public view of an order behind a payment link. It deliberately leaves out
+// internal IDs and customer details. PaymentStatus is set while a payment is being processed.
+type PaymentLink struct {
+	BusinessName  string         `json:"business_name"`
+	Description   *string        `json:"description,omitempty"`
+	Amount        string         `json:"amount"`
+	AmountMinor   int64          `json:"amount_minor"`
+	Currency      string         `json:"currency"`
+	ExpiresAt     time.Time      `json:"expires_at"`
+	PaymentStatus *PaymentStatus `json:"payment_status,omitempty"`
+}
+
+// PaymentLinkPayment is the public result of paying through a link.
+type PaymentLinkPayment struct {
+	ID            string        `json:"id"`
+	Status        PaymentStatus `json:"status"`
+	Amount        string        `json:"amount"`
+	AmountMinor   int64         `json:"amount_minor"`
+	Currency      string        `json:"currency"`
+	FailureReason *string       `json:"failure_reason,omitempty"`
+}
+
+// PaymentLinkRoutes serves the public payment link API. It must be mounted without authentication.
+func PaymentLinkRoutes(db *sql.DB, payments *payment.Registry) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/{token}", func(w http.ResponseWriter, r *http.Request) { getPaymentLink(db, w, r) })
+	r.Post("/{token}", func(w http.ResponseWriter, r *http.Request) { payWithLink(db, payments, w, r) })
+	return r
+}
+
+// attachPaymentLinkRoutes registers the authenticated endpoints that renew an order's payment link
+// and email it to the customer.
+func attachPaymentLinkRoutes(r chi.Router, db *sql.DB, linkTTL time.Duration, publicURL string) {
+	r.Post("/{orderID}/payment-link", func(w http.ResponseWriter, r *http.Request) { renewPaymentLink(db, linkTTL, w, r) })
+	r.Post("/{orderID}/payment-link/send", func(w http.ResponseWriter, r *http.Request) { sendPaymentLink(db, publicURL, w, r) })
+}
+
+// getPaymentLink handles GET /pay/{token}
+//
+// @Summary      Get a payment link
+// @Description  Public endpoint returning what the customer is asked to pay. Links of paid, cancelled or expired orders return an error with a state explaining why.
+// @Tags         payment-links
+// @Produce      json
+// @Param        token  path      string  true  "Payment link token"
+// @Success      200    {object}  PaymentLink
+// @Failure      404    {object}  ErrorResponse
+// @Failure      409    {object}  PaymentLinkErrorResponse  "Order already paid"
+// @Failure      410    {object}  PaymentLinkErrorResponse  "Link expired, order cancelled or business unavailable"
+// @Router       /pay/{token} [get]
+func getPaymentLink(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	_, link, ok := loadPaymentLink(ctx, db, w, chi.URLParam(r, "token"))
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(link)
+}
+
+// payWithLink handles POST /pay/{token}
+//
+// @Summary      Pay through a payment link
+// @Description  Public endpoint charging the order amount through the configured payment provider. 201 means the order is paid; 202 means the provider is still processing.
+// @Tags         payment-links
+// @Accept       json
+// @Produce      json
+// @Param        token    path      string          true   "Payment link token"
+// @Param        payload  body      PaymentPayload  false  "Payment method"
+// @Success      201      {object}  PaymentLinkPayment
+// @Success      202      {object}  PaymentLinkPayment
+// @Failure      402      {object}  ErrorResponse  "Payment declined"
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  PaymentLinkErrorResponse  "Order already paid or a payment is in progress"
+// @Failure      410      {object}  PaymentLinkErrorResponse  "Link expired, order cancelled or business unavailable"
+// @Failure      502      {object}  ErrorResponse  "Payment provider error"
+// @Router       /pay/{token} [post]
+func payWithLink(db *sql.DB, payments *payment.Registry, w http.ResponseWriter, r *http.Request) {
+
+	defer r.Body.Close()
+	var p PaymentPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		httpx.WriteBadRequest(w)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), providerTimeout)
+	defer cancel()
+
+	ord, _, ok := loadPaymentLink(ctx, db, w, chi.URLParam(r, "token"))
+	if !ok {
+		return
+	}
+
+	pay, ok := collectPayment(ctx, db, w, payments.Default(), ord, strings.TrimSpace(p.PaymentMethod), "")
+	if !ok {
+		return
+	}
+
+	status := http.StatusCreated
+	if pay.Status == PaymentProcessing {
+		status = http.StatusAccepted
+	}
+	httpx.WriteJSON(w, status, PaymentLinkPayment{
+		ID:            pay.ID,
+		Status:        pay.Status,
+		Amount:        pay.Amount,
+		AmountMinor:   pay.AmountMinor,
+		Currency:      pay.Currency,
+		FailureReason: pay.FailureReason,
+	})
+}
+
+// loadPaymentLink resolves a token to its order and checks the link can still be used.
+// Returns false after writing an error response.
+func loadPaymentLink(ctx context.Context, db *sql.DB, w http.ResponseWriter, token string) (Order, PaymentLink, bool) {
+	logger := slog.Default().With(
+		slog.String("component", "orders"),
+		slog.String("op", "loadPaymentLink"),
+	)
+
+	if !strings.HasPrefix(token, paymentLinkPrefix) {
+		httpx.WriteErr(w, http.StatusNotFound, "payment link not found")
+		return Order{}, PaymentLink{}, false
+	}
+
+	var (
+		businessName  string
+		archived      bool
+		paymentStatus *PaymentStatus
+	)
+	ord, err := scanOrder(db.QueryRowContext(ctx, `
+		SELECT `+orderColumns+`,
+			(SELECT b.name FROM business b WHERE b.id = o.business_id),
+			(SELECT b.archived_at IS NOT NULL FROM business b WHERE b.id = o.business_id),
+			(SELECT p.status FROM payment p WHERE p.order_id = o.id AND p.status IN ('pending', 'processing') LIMIT 1)
+		FROM "order" o
+		WHERE o.payment_link_token = $1`, token,
+	), &businessName, &archived, &paymentStatus)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusNotFound, "payment link not found")
+		return Order{}, PaymentLink{}, false
+	} else if err != nil {
+		logger.Error("query payment link failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Order{}, PaymentLink{}, false
+	}
+
+	switch {
+	case ord.Status == StatusPaid || ord.Status == StatusFulfilled || ord.Status == StatusRefunded || ord.Status == StatusPartiallyRefunded:
+		writeLinkErr(w, http.StatusConflict, LinkStatePaid, "this order has already been paid")
+		return Order{}, PaymentLink{}, false
+	case ord.Status == StatusCancelled:
+		writeLinkErr(w, http.StatusGone, LinkStateCancelled, "this order was cancelled")
+		return Order{}, PaymentLink{}, false
+	case archived:
+		writeLinkErr(w, http.StatusGone, LinkStateUnavailable, "this business no longer accepts payments")
+		return Order{}, PaymentLink{}, false
+	case ord.Status == StatusExpired || !time.Now().Before(ord.PaymentLinkExpiresAt):
+		writeLinkErr(w, http.StatusGone, LinkStateExpired, "this payment link has expired")
+		return Order{}, PaymentLink{}, false
+	}
+
+	return ord, PaymentLink{
+		BusinessName:  businessName,
+		Description:   ord.Description,
+		Amount:        ord.Amount,
+		AmountMinor:   ord.AmountMinor,
+		Currency:      ord.Currency,
+		ExpiresAt:     ord.PaymentLinkExpiresAt,
+		PaymentStatus: paymentStatus,
+	}, true
+}
+
+func writeLinkErr(w http.ResponseWriter, code int, state, msg string) {
+	httpx.WriteJSON(w, code, PaymentLinkErrorResponse{Error: msg, State: state})
+}
+
+// renewPaymentLink handles POST /api/orders/{orderID}/payment-link
+//
+// @Summary      Renew an order's payment link
+// @Description  Issues a new payment link token for a pending order and restarts its expiry. The previous link stops working.
+// @Tags         orders
+// @Produce      json
+// @Param        orderID  path      string  true  "Order ID"
+// @Success      200      {object}  Order
+// @Failure      403      {object}  businessuser.MissingPermissionResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse  "Order is not pending"
+// @Router       /api/orders/{orderID}/payment-link [post]
+func renewPaymentLink(db *sql.DB, linkTTL time.Duration, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	orderID := chi.URLParam(r, "orderID")
+	logger := slog.Default().With(
+		slog.String("component", "orders"),
+		slog.String("op", "renewPaymentLink"),
+		slog.String("order_id", orderID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if _, ok := loadAuthorizedOrder(ctx, db, w, orderID, u, businessuser.PermOrdersUpdate); !ok {
+		return
+	}
+
+	ord, err := scanOrder(db.QueryRowContext(ctx, `
+		UPDATE "order" SET payment_link_token = $2, payment_link_expires_at = now() + make_interval(secs => $3), updated_at = now()
+		WHERE id = $1 AND status = $4
+		RETURNING `+orderColumns,
+		orderID, newPaymentLinkToken(), linkTTL.Seconds(), StatusPending,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusConflict, "only pending orders have a payment link")
+		return
+	} else if err != nil {
+		logger.Error("renew payment link failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ord)
+}
+
+// newPaymentLinkToken returns an unguessable token carrying 256 bits of randomness.
+func newPaymentLinkToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return paymentLinkPrefix + hex.EncodeToString(b)
+}
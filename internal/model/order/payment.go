@@ -0,0 +1,160 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+	"backend/internal/payment"
+)
+
+// PaymentPayload selects how the order is paid. PaymentMethod is a provider-specific token;
+// with the simulator it is one of sim_succeed (default), sim_decline, sim_fail, sim_delay or sim_delay_decline.
+type PaymentPayload struct {
+	PaymentMethod string `json:"payment_method" example:"sim_succeed"`
+}
+
+// providerTimeout bounds requests that call out to the payment provider.
+const providerTimeout = 15 * time.Second
+
+// attachPaymentRoutes registers the payment endpoints of an order.
+func attachPaymentRoutes(r chi.Router, db *sql.DB, payments *payment.Registry) {
+	r.Post("/{orderID}/payments", func(w http.ResponseWriter, r *http.Request) { createPayment(db, payments, w, r) })
+	r.Get("/{orderID}/payments", func(w http.ResponseWriter, r *http.Request) { getPayments(db, w, r) })
+}
+
+// createPayment handles POST /api/orders/{orderID}/payments
+//
+// @Summary      Pay an order
+// @Description  Charges the full order amount through the configured payment provider. 201 means the payment succeeded and the order is paid; 202 means the provider is still processing and the order is updated once its webhook arrives.
+// @Tags         orders
+// @Accept       json
+// @Produce      json
+// @Param        orderID  path      string          true   "Order ID"
+// @Param        payload  body      PaymentPayload  false  "Payment method"
+// @Success      201      {object}  Payment
+// @Success      202      {object}  Payment
+// @Failure      402      {object}  ErrorResponse  "Payment declined"
+// @Failure      403      {object}  businessuser.MissingPermissionResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse  "Order is not pending or already has a payment"
+// @Failure      502      {object}  ErrorResponse  "Payment provider error"
+// @Router       /api/orders/{orderID}/payments [post]
+func createPayment(db *sql.DB, payments *payment.Registry, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p PaymentPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		httpx.WriteBadRequest(w)
+		return
+	}
+
+	orderID := chi.URLParam(r, "orderID")
+	logger := slog.Default().With(
+		slog.String("component", "orders"),
+		slog.String("op", "createPayment"),
+		slog.String("order_id", orderID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), providerTimeout)
+	defer cancel()
+
+	ord, ok := loadAuthorizedOrder(ctx, db, w, orderID, u, businessuser.PermOrdersUpdate)
+	if !ok {
+		return
+	}
+
+	var userID string
+	if err := db.QueryRowContext(ctx, `SELECT id FROM "user" WHERE firebase_id = $1`, u.UID).Scan(&userID); err != nil {
+		logger.Error("query user failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	pay, ok := collectPayment(ctx, db, w, payments.Default(), ord, strings.TrimSpace(p.PaymentMethod), userID)
+	if !ok {
+		return
+	}
+
+	status := http.StatusCreated
+	if pay.Status == PaymentProcessing {
+		status = http.StatusAccepted
+	}
+	httpx.WriteJSON(w, status, pay)
+}
+
+// getPayments handles GET /api/orders/{orderID}/payments
+//
+// @Summary      List an order's payments
+// @Description  Returns every payment attempt of the order, oldest first. Orders created by another member require the orders:read_all permission.
+// @Tags         orders
+// @Produce      json
+// @Param        orderID  path      string  true  "Order ID"
+// @Success      200      {array}   Payment
+// @Failure      403      {object}  businessuser.MissingPermissionResponse
+// @Failure      404      {object}  ErrorResponse
+// @Router       /api/orders/{orderID}/payments [get]
+func getPayments(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	orderID := chi.URLParam(r, "orderID")
+	logger := slog.Default().With(
+		slog.String("component", "orders"),
+		slog.String("op", "getPayments"),
+		slog.String("order_id", orderID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if _, ok := loadAuthorizedOrder(ctx, db, w, orderID, u, businessuser.PermOrdersRead); !ok {
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT `+paymentColumns+` FROM payment WHERE order_id = $1 ORDER BY created_at, id`, orderID)
+	if err != nil {
+		logger.Error("query payments failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+
+	payments := []Payment{}
+	for rows.Next() {
+		pay, err := scanPayment(rows)
+		if err != nil {
+			logger.Error("scan payment failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		payments = append(payments, pay)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("iterate payments failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(payments)
+}
@@ -0,0 +1,191 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"backend/internal/money"
+	"backend/internal/pagination"
+)
+
+const (
+	scopeMine     = "mine"     // only orders created by the caller
+	scopeBusiness = "business" // every order of the business; requires orders:read_all
+)
+
+// listParams holds the parsed query string of GET /api/orders.
+type listParams struct {
+	Scope         string // scopeMine or scopeBusiness
+	CreatedBy     string
+	Limit         int
+	Cursor        *pagination.Cursor
+	Ascending     bool
+	Statuses      []Status
+	Currency      string
+	AmountMin     *int64
+	AmountMax     *int64
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	CustomerID    string
+	CustomerEmail string
+}
+
+// parseListParams validates the list filters. Amount bounds are decimals in the currency
+// given by the currency filter, which is therefore required when they are used.
+func parseListParams(q url.Values) (listParams, error) {
+	p := listParams{Scope: scopeMine}
+
+	switch v := q.Get("scope"); v {
+	case "", scopeMine:
+	case scopeBusiness:
+		p.Scope = scopeBusiness
+	default:
+		return p, errors.New("scope must be mine or business")
+	}
+
+	if v := strings.TrimSpace(q.Get("created_by")); v != "" {
+		if p.Scope != scopeBusiness {
+			return p, errors.New("created_by requires scope=business")
+		}
+		if _, err := uuid.Parse(v); err != nil {
+			return p, errors.New("created_by must be a user ID")
+		}
+		p.CreatedBy = v
+	}
+
+	switch q.Get("sort") {
+	case "", "-created_at":
+	case "created_at":
+		p.Ascending = true
+	default:
+		return p, errors.New("sort must be created_at or -created_at")
+	}
+
+	var err error
+	if p.Limit, p.Cursor, err = pagination.Parse(q, p.Ascending); err != nil {
+		return p, err
+	}
+
+	if v := q.Get("status"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			st := Status(strings.TrimSpace(s))
+			if !st.valid() {
+				return p, fmt.Errorf("unknown status %q", st)
+			}
+			p.Statuses = append(p.Statuses, st)
+		}
+	}
+
+	p.Currency = strings.ToUpper(strings.TrimSpace(q.Get("currency")))
+	if p.Currency != "" {
+		if !money.KnownCurrency(p.Currency) {
+			return p, fmt.Errorf("currency %q is not a supported ISO 4217 currency", p.Currency)
+		}
+	}
+
+	for _, bound := range []struct {
+		name string
+		dst  **int64
+	}{{"amount_min", &p.AmountMin}, {"amount_max", &p.AmountMax}} {
+		v := q.Get(bound.name)
+		if v == "" {
+			continue
+		}
+		if p.Currency == "" {
+			return p, fmt.Errorf("%s requires the currency filter", bound.name)
+		}
+		a, err := money.Parse(v, p.Currency)
+		if err != nil {
+			return p, fmt.Errorf("%s: %w", bound.name, err)
+		}
+		*bound.dst = &a.Minor
+	}
+
+	for _, bound := range []struct {
+		name string
+		dst  **time.Time
+	}{{"created_from", &p.CreatedFrom}, {"created_to", &p.CreatedTo}} {
+		v := q.Get(bound.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return p, fmt.Errorf("%s must be an RFC 3339 timestamp", bound.name)
+		}
+		*bound.dst = &t
+	}
+
+	if v := strings.TrimSpace(q.Get("customer_id")); v != "" {
+		if _, err := uuid.Parse(v); err != nil {
+			return p, errors.New("customer_id must be a customer ID")
+		}
+		p.CustomerID = v
+	}
+	p.CustomerEmail = strings.TrimSpace(q.Get("customer_email"))
+	return p, nil
+}
+
+// where appends the filter conditions to conds/args, numbering placeholders after the existing args.
+func (p listParams) where(conds []string, args []any) ([]string, []any) {
+	add := func(cond string, v any) {
+		args = append(args, v)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if p.CreatedBy != "" {
+		add("o.created_by = ?::uuid", p.CreatedBy)
+	}
+	if len(p.Statuses) > 0 {
+		placeholders := make([]string, len(p.Statuses))
+		for i, s := range p.Statuses {
+			args = append(args, s)
+			placeholders[i] = "$" + strconv.Itoa(len(args))
+		}
+		conds = append(conds, "o.status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if p.Currency != "" {
+		add("o.currency = ?", p.Currency)
+	}
+	if p.AmountMin != nil {
+		add("o.amount_minor >= ?", *p.AmountMin)
+	}
+	if p.AmountMax != nil {
+		add("o.amount_minor <= ?", *p.AmountMax)
+	}
+	if p.CreatedFrom != nil {
+		add("o.created_at >= ?", *p.CreatedFrom)
+	}
+	if p.CreatedTo != nil {
+		add("o.created_at < ?", *p.CreatedTo)
+	}
+	if p.CustomerID != "" {
+		add("o.customer_id = ?::uuid", p.CustomerID)
+	}
+	if p.CustomerEmail != "" {
+		add("EXISTS (SELECT 1 FROM customer c WHERE c.id = o.customer_id AND lower(c.email) = lower(?))", p.CustomerEmail)
+	}
+	if p.Cursor != nil {
+		op := "<"
+		if p.Ascending {
+			op = ">"
+		}
+		args = append(args, p.Cursor.CreatedAt, p.Cursor.ID)
+		conds = append(conds, fmt.Sprintf("(o.created_at, o.id) %s ($%d, $%d::uuid)", op, len(args)-1, len(args)))
+	}
+	return conds, args
+}
+
+// orderBy returns the ORDER BY clause matching the keyset direction.
+func (p listParams) orderBy() string {
+	if p.Ascending {
+		return "o.created_at ASC, o.id ASC"
+	}
+	return "o.created_at DESC, o.id DESC"
+}
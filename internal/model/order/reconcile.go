@@ -0,0 +1,151 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"backend/internal/payment"
+)
+
+const (
+	// stalePaymentAge is how long a payment, or a refund, may stay pending without an answer from
+	// the provider before RunReconciler gives up on it. It is far longer than providerTimeout.
+	stalePaymentAge = 15 * time.Minute
+	// reconcileBatchSize bounds how many records one reconciler pass handles per kind.
+	reconcileBatchSize = 100
+)
+
+// RunReconciler repairs payments and refunds that a crash or a failed write left pending after
+// the provider was called, every interval until ctx is cancelled. Those stuck without a provider
+// answer are marked failed and queued for review, since the provider may have moved the money
+// anyway. Several replicas may run it concurrently.
+func RunReconciler(ctx context.Context, db *sql.DB, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if n, err := abandonStalePayments(ctx, db); err != nil {
+				slog.Warn("reconcile stale payments failed", slog.Any("err", err))
+			} else if n > 0 {
+				slog.Warn("stale payments abandoned and queued for review", slog.Int("count", n))
+			}
+			if n, err := abandonStaleRefunds(ctx, db); err != nil {
+				slog.Warn("reconcile stale refunds failed", slog.Any("err", err))
+			} else if n > 0 {
+				slog.Warn("stale refunds abandoned and queued for review", slog.Int("count", n))
+			}
+		}
+	}
+}
+
+// abandonStalePayments marks failed the payments pending for longer than stalePaymentAge and
+// returns how many it marked.
+func abandonStalePayments(ctx context.Context, db *sql.DB) (int, error) {
+	n := 0
+	for n < reconcileBatchSize {
+		ok, err := abandonStalePayment(ctx, db)
+		if err != nil {
+			return n, err
+		}
+		if !ok {
+			break
+		}
+		n++
+	}
+	return n, nil
+}
+
+// abandonStalePayment marks the oldest stale pending payment failed, which makes its order
+// payable again, and queues it for review. It returns false when there is none.
+func abandonStalePayment(ctx context.Context, db *sql.DB) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	pay, err := scanPayment(tx.QueryRowContext(ctx, `
+		UPDATE payment SET status = $2, failure_reason = 'abandoned', updated_at = now()
+		WHERE id = (
+			SELECT id FROM payment
+			WHERE status = $1 AND created_at <= now() - make_interval(secs => $3)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+paymentColumns,
+		PaymentPending, PaymentFailed, stalePaymentAge.Seconds(),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if err := queueReview(ctx, tx, pay.Provider, payment.EventPaymentFailed, pay.ID, derefString(pay.ProviderRef), "", pay.OrderID,
+		fmt.Sprintf("payment %s got no answer from the provider within %s and was marked failed; check whether the provider captured it", pay.ID, stalePaymentAge),
+	); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// abandonStaleRefunds marks failed the refunds pending for longer than stalePaymentAge that never
+// got a provider reference, and returns how many it marked. Refunds with a reference wait for
+// the provider's webhook.
+func abandonStaleRefunds(ctx context.Context, db *sql.DB) (int, error) {
+	n := 0
+	for n < reconcileBatchSize {
+		ok, err := abandonStaleRefund(ctx, db)
+		if err != nil {
+			return n, err
+		}
+		if !ok {
+			break
+		}
+		n++
+	}
+	return n, nil
+}
+
+// abandonStaleRefund marks the oldest stale pending refund failed, which makes its amount
+// refundable again, and queues it for review. It returns false when there is none.
+func abandonStaleRefund(ctx context.Context, db *sql.DB) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rf, err := scanRefund(tx.QueryRowContext(ctx, `
+		UPDATE refund SET status = $2, failure_reason = 'abandoned', updated_at = now()
+		WHERE id = (
+			SELECT id FROM refund
+			WHERE status = $1 AND provider_ref IS NULL AND created_at <= now() - make_interval(secs => $3)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+refundColumns,
+		RefundPending, RefundFailed, stalePaymentAge.Seconds(),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if err := queueReview(ctx, tx, rf.Provider, payment.EventRefundFailed, rf.ID, "", "", rf.OrderID,
+		fmt.Sprintf("refund %s got no answer from the provider within %s and was marked failed; check whether the provider returned the money", rf.ID, stalePaymentAge),
+	); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
@@ -0,0 +1,380 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+	"backend/internal/money"
+	"backend/internal/payment"
+)
+
+// RefundStatus is the local state of a refund.
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "pending" // the provider reports the result later by webhook
+	RefundSucceeded RefundStatus = "succeeded"
+	RefundFailed    RefundStatus = "failed"
+)
+
+// Refund returns part or all of an order's captured payment to the customer.
+type Refund struct {
+	ID            string       `json:"id"`
+	OrderID       string       `json:"order_id"`
+	PaymentID     string       `json:"payment_id"`
+	BusinessID    string       `json:"business_id"`
+	Provider      string       `json:"provider"`
+	ProviderRef   *string      `json:"provider_ref,omitempty"`
+	Status        RefundStatus `json:"status"`
+	Amount        string       `json:"amount"`
+	AmountMinor   int64        `json:"amount_minor"`
+	Currency      string       `json:"currency"`
+	Reason        string       `json:"reason"`
+	FailureReason *string      `json:"failure_reason,omitempty"`
+	CreatedBy     *string      `json:"created_by,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+	RefundedAt    *time.Time   `json:"refunded_at,omitempty"`
+}
+
+// RefundPayload is the body accepted by POST /api/orders/{orderID}/refunds.
+// Amount is a decimal in the order's currency; when omitted the whole remaining amount is refunded.
+type RefundPayload struct {
+	Amount json.Number `json:"amount" swaggertype:"string" example:"5.00"`
+	Reason string      `json:"reason" example:"customer returned the item"`
+}
+
+// refundColumns is the column list scanned by scanRefund.
+const refundColumns = `id, order_id, payment_id, business_id, provider, provider_ref, status, amount_minor, currency, reason, failure_reason, created_by, created_at, updated_at, refunded_at`
+
+func scanRefund(row rowScanner) (Refund, error) {
+	var rf Refund
+	err := row.Scan(&rf.ID, &rf.OrderID, &rf.PaymentID, &rf.BusinessID, &rf.Provider, &rf.ProviderRef, &rf.Status,
+		&rf.AmountMinor, &rf.Currency, &rf.Reason, &rf.FailureReason, &rf.CreatedBy, &rf.CreatedAt, &rf.UpdatedAt, &rf.RefundedAt)
+	rf.Amount = money.FromMinor(rf.AmountMinor, rf.Currency).String()
+	return rf, err
+}
+
+// attachRefundRoutes registers the refund endpoints of an order.
+func attachRefundRoutes(r chi.Router, db *sql.DB, payments *payment.Registry) {
+	r.Post("/{orderID}/refunds", func(w http.ResponseWriter, r *http.Request) { createRefund(db, payments, w, r) })
+	r.Get("/{orderID}/refunds", func(w http.ResponseWriter, r *http.Request) { getOrderRefunds(db, w, r) })
+}
+
+// createRefund handles POST /api/orders/{orderID}/refunds
+//
+// @Summary      Refund an order
+// @Description  Refunds part or all of the order's captured payment through its provider. The refunded total can never exceed the captured amount; pending refunds count against it. A full refund moves the order to refunded, a partial one to partially_refunded. 202 means the provider confirms the refund later. Send an Idempotency-Key to retry safely.
+// @Tags         orders
+// @Accept       json
+// @Produce      json
+// @Param        orderID          path      string         true   "Order ID"
+// @Param        Idempotency-Key  header    string         false  "Makes the request safe to retry"
+// @Param        payload          body      RefundPayload  true   "Refund amount and reason"
+// @Success      201              {object}  Refund
+// @Success      202              {object}  Refund
+// @Failure      400              {object}  ErrorResponse  "Missing reason or amount exceeds the refundable amount"
+// @Failure      402              {object}  ErrorResponse  "Refund declined by the provider"
+// @Failure      403              {object}  businessuser.MissingPermissionResponse
+// @Failure      404              {object}  ErrorResponse
+// @Failure      409              {object}  ErrorResponse  "Order is not paid or already fully refunded"
+// @Failure      502              {object}  ErrorResponse  "Payment provider error"
+// @Router       /api/orders/{orderID}/refunds [post]
+func createRefund(db *sql.DB, payments *payment.Registry, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p RefundPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		httpx.WriteBadRequest(w)
+		return
+	}
+	p.Reason = strings.TrimSpace(p.Reason)
+	if p.Reason == "" {
+		httpx.WriteErr(w, http.StatusBadRequest, "reason is required")
+		return
+	}
+
+	orderID := chi.URLParam(r, "orderID")
+	logger := slog.Default().With(
+		slog.String("component", "orders"),
+		slog.String("op", "createRefund"),
+		slog.String("order_id", orderID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), providerTimeout)
+	defer cancel()
+
+	ord, ok := loadAuthorizedOrder(ctx, db, w, orderID, u, businessuser.PermOrdersRefund)
+	if !ok {
+		return
+	}
+	switch ord.Status {
+	case StatusPaid, StatusFulfilled, StatusPartiallyRefunded:
+	default:
+		httpx.WriteErr(w, http.StatusConflict, "only paid orders can be refunded, order is "+string(ord.Status))
+		return
+	}
+
+	var userID string
+	if err := db.QueryRowContext(ctx, `SELECT id FROM "user" WHERE firebase_id = $1`, u.UID).Scan(&userID); err != nil {
+		logger.Error("query user failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	rf, pay, ok := reserveRefund(ctx, db, w, logger, ord, p, userID)
+	if !ok {
+		return
+	}
+	logger = logger.With(slog.String("refund_id", rf.ID))
+
+	provider, ok := payments.Get(pay.Provider)
+	if !ok || pay.ProviderRef == nil {
+		logger.Error("payment provider not configured", slog.String("provider", pay.Provider))
+		failRefund(ctx, db, logger, rf.ID, "provider_unavailable")
+		httpx.WriteErr(w, http.StatusBadGateway, "payment provider unavailable")
+		return
+	}
+
+	res, err := provider.Refund(ctx, payment.RefundRequest{
+		PaymentRef:     *pay.ProviderRef,
+		Amount:         money.FromMinor(rf.AmountMinor, rf.Currency),
+		Reason:         rf.Reason,
+		IdempotencyKey: rf.ID,
+	})
+	if err == nil && res.Status == payment.StatusFailed {
+		err = &payment.DeclineError{Reason: "refund_failed"}
+	}
+	if err != nil {
+		failRefund(ctx, db, logger, rf.ID, failureReason(err))
+		writeProviderResponse(w, logger, err)
+		return
+	}
+
+	if res.Status != payment.StatusSucceeded {
+		// Without the reference the provider's webhook cannot find the refund, so it is
+		// recorded even if the request deadline has passed.
+		recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+		defer cancel()
+		rf, err = scanRefund(db.QueryRowContext(recordCtx, `
+			UPDATE refund SET provider_ref = $2, updated_at = now()
+			WHERE id = $1
+			RETURNING `+refundColumns,
+			rf.ID, res.Ref,
+		))
+		if err != nil {
+			logger.Error("update pending refund failed", slog.String("provider_ref", res.Ref), slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		httpx.WriteJSON(w, http.StatusAccepted, rf)
+		return
+	}
+
+	// The money is returned. Record that without the request deadline, which the provider call may
+	// have used up.
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	recorded, err := recordRefund(recordCtx, db, rf.ID, res.Ref, Actor{Kind: ActorUser, UserID: userID})
+	var te *TransitionError
+	switch {
+	case err == nil:
+	case errors.As(err, &te), errors.Is(err, errOrderNotFound):
+		logger.Warn("refund succeeded for an order that moved on, queued for review", slog.Any("err", err))
+	default:
+		// RunReconciler marks the still pending refund abandoned and queues it for review.
+		logger.Error("record succeeded refund failed", slog.String("provider_ref", res.Ref), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusCreated, recorded)
+}
+
+// reserveRefund validates the amount against what is still refundable and records a pending refund.
+// The captured payment row is locked meanwhile so concurrent refunds cannot together exceed it.
+// Returns false after writing an error response.
+func reserveRefund(ctx context.Context, db *sql.DB, w http.ResponseWriter, logger *slog.Logger, ord Order, p RefundPayload, userID string) (Refund, Payment, bool) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("begin tx failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Refund{}, Payment{}, false
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	pay, err := scanPayment(tx.QueryRowContext(ctx,
+		`SELECT `+paymentColumns+` FROM payment WHERE order_id = $1 AND status = $2 FOR UPDATE`,
+		ord.ID, PaymentSucceeded,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusConflict, "order has no captured payment to refund")
+		return Refund{}, Payment{}, false
+	} else if err != nil {
+		logger.Error("query captured payment failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Refund{}, Payment{}, false
+	}
+
+	var reserved int64
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(sum(amount_minor), 0) FROM refund
+		WHERE payment_id = $1 AND status IN ($2, $3)`,
+		pay.ID, RefundPending, RefundSucceeded,
+	).Scan(&reserved); err != nil {
+		logger.Error("sum refunds failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Refund{}, Payment{}, false
+	}
+	remaining := money.FromMinor(pay.AmountMinor-reserved, pay.Currency)
+	if remaining.Minor <= 0 {
+		httpx.WriteErr(w, http.StatusConflict, "order is already fully refunded")
+		return Refund{}, Payment{}, false
+	}
+
+	amount := remaining
+	if p.Amount != "" {
+		if amount, err = parseAmount(p.Amount, pay.Currency); err != nil {
+			httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+			return Refund{}, Payment{}, false
+		}
+		if amount.Minor > remaining.Minor {
+			httpx.WriteErr(w, http.StatusBadRequest, fmt.Sprintf("amount exceeds the refundable amount of %s %s", remaining, remaining.Currency))
+			return Refund{}, Payment{}, false
+		}
+	}
+
+	rf, err := scanRefund(tx.QueryRowContext(ctx, `
+		INSERT INTO refund (order_id, payment_id, business_id, provider, status, amount_minor, currency, reason, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+refundColumns,
+		ord.ID, pay.ID, ord.BusinessID, pay.Provider, RefundPending, amount.Minor, amount.Currency, p.Reason, userID,
+	))
+	if err != nil {
+		logger.Error("insert refund failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Refund{}, Payment{}, false
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("commit failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Refund{}, Payment{}, false
+	}
+	return rf, pay, true
+}
+
+// completeRefund marks a refund succeeded and posts it to the ledger inside tx, then moves the
+// order along with refundTransition.
+func completeRefund(ctx context.Context, tx *sql.Tx, refundID, providerRef string, actor Actor) (Refund, error) {
+	rf, err := succeedRefund(ctx, tx, refundID, providerRef)
+	if err != nil {
+		return Refund{}, err
+	}
+	if err := refundTransition(ctx, tx, rf, actor); err != nil {
+		return Refund{}, err
+	}
+	return rf, nil
+}
+
+// recordRefund marks a refund succeeded, posts it to the ledger and moves its order along in one
+// transaction, like completeRefund. When the order cannot move (e.g. it is no longer in a
+// refundable status) or no longer exists, the refund and its ledger entries are recorded all the
+// same, the refund is put in the review queue and the *TransitionError or errOrderNotFound is
+// returned.
+func recordRefund(ctx context.Context, db *sql.DB, refundID, providerRef string, actor Actor) (Refund, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Refund{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rf, err := succeedRefund(ctx, tx, refundID, providerRef)
+	if err != nil {
+		return Refund{}, err
+	}
+	err = refundTransition(ctx, tx, rf, actor)
+	var te *TransitionError
+	switch {
+	case err == nil:
+	case errors.As(err, &te), errors.Is(err, errOrderNotFound):
+		if qerr := queueReview(ctx, tx, rf.Provider, payment.EventRefundSucceeded, rf.ID, "", providerRef, rf.OrderID,
+			fmt.Sprintf("refund %s succeeded but %s", rf.ID, err.Error())); qerr != nil {
+			return Refund{}, qerr
+		}
+	default:
+		return Refund{}, err
+	}
+	if cerr := tx.Commit(); cerr != nil {
+		return Refund{}, cerr
+	}
+	return rf, err
+}
+
+// succeedRefund marks a refund succeeded and posts it to the ledger inside tx.
+func succeedRefund(ctx context.Context, tx *sql.Tx, refundID, providerRef string) (Refund, error) {
+	rf, err := scanRefund(tx.QueryRowContext(ctx, `
+		UPDATE refund SET status = $2, provider_ref = $3, failure_reason = NULL, refunded_at = now(), updated_at = now()
+		WHERE id = $1
+		RETURNING `+refundColumns,
+		refundID, RefundSucceeded, providerRef,
+	))
+	if err != nil {
+		return Refund{}, err
+	}
+	return rf, postRefund(ctx, tx, rf)
+}
+
+// refundTransition moves the order of a succeeded refund to refunded once the succeeded refunds
+// cover the captured amount, otherwise to partially_refunded.
+func refundTransition(ctx context.Context, tx *sql.Tx, rf Refund, actor Actor) error {
+	var captured, refunded int64
+	if err := tx.QueryRowContext(ctx, `
+		SELECT p.amount_minor,
+			(SELECT COALESCE(sum(rf.amount_minor), 0) FROM refund rf WHERE rf.payment_id = p.id AND rf.status = $2)
+		FROM payment p WHERE p.id = $1`,
+		rf.PaymentID, RefundSucceeded,
+	).Scan(&captured, &refunded); err != nil {
+		return err
+	}
+
+	to := StatusPartiallyRefunded
+	if refunded >= captured {
+		to = StatusRefunded
+	}
+	note := fmt.Sprintf("refund %s of %s %s: %s", rf.ID, rf.Amount, rf.Currency, rf.Reason)
+	_, err := transition(ctx, tx, rf.OrderID, to, actor, note)
+	return err
+}
+
+// failRefund marks a refund failed so its amount becomes refundable again.
+// The update does not depend on the request deadline, which a slow provider may have used up.
+func failRefund(ctx context.Context, db *sql.DB, logger *slog.Logger, refundID, reason string) {
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+	if _, err := db.ExecContext(recordCtx, `
+		UPDATE refund SET status = $2, failure_reason = $3, updated_at = now()
+		WHERE id = $1`,
+		refundID, RefundFailed, reason,
+	); err != nil {
+		logger.Error("mark refund failed failed", slog.Any("err", err))
+	}
+}
@@ -0,0 +1,190 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+	"backend/internal/pagination"
+)
+
+// ListRefundsResponse is one page of refunds. NextCursor is null on the last page.
+type ListRefundsResponse struct {
+	Refunds    []Refund `json:"refunds"`
+	NextCursor *string  `json:"next_cursor"`
+}
+
+// RefundRoutes serves the business-wide refund listing mounted at /api/refunds.
+func RefundRoutes(db *sql.DB) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) { listRefunds(db, w, r) })
+	return r
+}
+
+// getOrderRefunds handles GET /api/orders/{orderID}/refunds
+//
+// @Summary      List an order's refunds
+// @Description  Returns every refund of the order, oldest first, including failed ones. Orders created by another member require the orders:read_all permission.
+// @Tags         orders
+// @Produce      json
+// @Param        orderID  path      string  true  "Order ID"
+// @Success      200      {array}   Refund
+// @Failure      403      {object}  businessuser.MissingPermissionResponse
+// @Failure      404      {object}  ErrorResponse
+// @Router       /api/orders/{orderID}/refunds [get]
+func getOrderRefunds(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	orderID := chi.URLParam(r, "orderID")
+	logger := slog.Default().With(
+		slog.String("component", "orders"),
+		slog.String("op", "getOrderRefunds"),
+		slog.String("order_id", orderID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if _, ok := loadAuthorizedOrder(ctx, db, w, orderID, u, businessuser.PermOrdersRead); !ok {
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT `+refundColumns+` FROM refund WHERE order_id = $1 ORDER BY created_at, id`, orderID)
+	if err != nil {
+		logger.Error("query refunds failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+
+	refunds := []Refund{}
+	for rows.Next() {
+		rf, err := scanRefund(rows)
+		if err != nil {
+			logger.Error("scan refund failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		refunds = append(refunds, rf)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("iterate refunds failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(refunds)
+}
+
+// listRefunds handles GET /api/refunds?business_id=...
+//
+// @Summary      List a business's refunds
+// @Description  Returns a page of refunds across every order of the business, newest first. Requires the orders:read_all permission. If business_id is omitted and the authenticated user belongs to exactly one business, that business is used. Pass next_cursor back as cursor to fetch the following page.
+// @Tags         refunds
+// @Produce      json
+// @Param        business_id  query     string  false  "Business ID"
+// @Param        status       query     string  false  "pending, succeeded or failed"
+// @Param        limit        query     int     false  "Page size (1-200, default 50)"
+// @Param        cursor       query     string  false  "next_cursor from the previous page"
+// @Success      200          {object}  ListRefundsResponse
+// @Failure      400          {object}  businessuser.AmbiguousBusinessResponse
+// @Failure      403          {object}  businessuser.MissingPermissionResponse
+// @Router       /api/refunds [get]
+func listRefunds(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	logger := slog.Default().With(
+		slog.String("component", "orders"),
+		slog.String("op", "listRefunds"),
+	)
+
+	q := r.URL.Query()
+	limit, after, err := pagination.Parse(q, false)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	status := RefundStatus(q.Get("status"))
+	switch status {
+	case "", RefundPending, RefundSucceeded, RefundFailed:
+	default:
+		httpx.WriteErr(w, http.StatusBadRequest, "status must be one of pending, succeeded, failed")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	bizID, ok := businessuser.ResolveBusinessID(ctx, db, w, q.Get("business_id"), u)
+	if !ok {
+		return
+	}
+	if !businessuser.AssertPermission(ctx, db, w, bizID, u, businessuser.PermOrdersReadAll) {
+		return
+	}
+
+	var afterAt *time.Time
+	var afterID *string
+	if after != nil {
+		afterAt, afterID = &after.CreatedAt, &after.ID
+	}
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+refundColumns+`
+		FROM refund
+		WHERE business_id = $1::uuid
+			AND ($2 = '' OR status = $2)
+			AND ($3::timestamptz IS NULL OR (created_at, id) < ($3, $4::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $5`,
+		bizID, string(status), afterAt, afterID, limit+1,
+	)
+	if err != nil {
+		logger.Error("query refunds failed", slog.String("business_id", bizID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+
+	refunds := make([]Refund, 0)
+	for rows.Next() {
+		rf, err := scanRefund(rows)
+		if err != nil {
+			logger.Error("scan refund row failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		refunds = append(refunds, rf)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("rows error after iteration", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	resp := ListRefundsResponse{Refunds: refunds}
+	if len(refunds) > limit {
+		last := refunds[limit-1]
+		next := pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+		resp.Refunds = refunds[:limit]
+		resp.NextCursor = &next
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,281 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/pagination"
+)
+
+// eventResolved marks a review entry an operator has dealt with.
+const eventResolved = "resolved"
+
+// ProviderEvent is a stored inbound provider event, or a review entry raised by this service
+// itself (event IDs starting with "local:"), e.g. a payment captured for a cancelled order.
+type ProviderEvent struct {
+	ID             int64           `json:"id"`
+	Provider       string          `json:"provider"`
+	EventID        string          `json:"event_id"`
+	Type           string          `json:"type"`
+	PaymentRef     *string         `json:"payment_ref,omitempty"`
+	RefundRef      *string         `json:"refund_ref,omitempty"`
+	Status         string          `json:"status"`
+	ReviewReason   *string         `json:"review_reason,omitempty"`
+	OrderID        *string         `json:"order_id,omitempty"`
+	ReceivedAt     time.Time       `json:"received_at"`
+	ResolvedAt     *time.Time      `json:"resolved_at,omitempty"`
+	ResolvedBy     *string         `json:"resolved_by,omitempty"`
+	ResolutionNote *string         `json:"resolution_note,omitempty"`
+	Payload        json.RawMessage `json:"payload,omitempty" swaggertype:"object"` // the provider's body, when it is JSON
+}
+
+// ListProviderEventsResponse is one page of provider events. NextCursor is null on the last page.
+type ListProviderEventsResponse struct {
+	Events     []ProviderEvent `json:"events"`
+	NextCursor *string         `json:"next_cursor"`
+}
+
+// ResolvePayload is the body accepted by POST /api/provider-events/{eventID}/resolve.
+type ResolvePayload struct {
+	Note string `json:"note" example:"refunded the customer from the provider dashboard"`
+}
+
+// providerEventColumns is the column list scanned by scanProviderEvent.
+const providerEventColumns = `id, provider, event_id, type, payment_ref, refund_ref, status, review_reason, order_id, received_at, resolved_at, resolved_by, resolution_note, payload`
+
+func scanProviderEvent(row rowScanner) (ProviderEvent, error) {
+	var ev ProviderEvent
+	var payload []byte
+	err := row.Scan(&ev.ID, &ev.Provider, &ev.EventID, &ev.Type, &ev.PaymentRef, &ev.RefundRef, &ev.Status, &ev.ReviewReason,
+		&ev.OrderID, &ev.ReceivedAt, &ev.ResolvedAt, &ev.ResolvedBy, &ev.ResolutionNote, &payload)
+	if len(payload) > 0 && json.Valid(payload) {
+		ev.Payload = payload
+	}
+	return ev, err
+}
+
+// ReviewRoutes serves the review queue of provider events to platform operators, i.e. users
+// with the custom claim admin: true, and must be mounted behind auth.RequirePlatformAdmin.
+// Events land there when a payment or refund result cannot be applied automatically; an
+// operator fixes the order or the money with the provider and then resolves the event with a note.
+func ReviewRoutes(db *sql.DB) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) { listProviderEvents(db, w, r) })
+	r.Get("/{eventID}", func(w http.ResponseWriter, r *http.Request) { getProviderEvent(db, w, r) })
+	r.Post("/{eventID}/resolve", func(w http.ResponseWriter, r *http.Request) { resolveProviderEvent(db, w, r) })
+	return r
+}
+
+// listProviderEvents handles GET /api/provider-events
+//
+// @Summary      List provider events
+// @Description  Returns a page of provider events, oldest first. By default only the review queue (status review) is listed. Platform admins only. Pass next_cursor back as cursor to fetch the following page.
+// @Tags         review
+// @Produce      json
+// @Param        status  query     string  false  "review (default), resolved, processed or ignored"
+// @Param        limit   query     int     false  "Page size (1-200, default 50)"
+// @Param        cursor  query     string  false  "next_cursor from the previous page"
+// @Success      200     {object}  ListProviderEventsResponse
+// @Failure      400     {object}  ErrorResponse
+// @Failure      403     {object}  ErrorResponse
+// @Router       /api/provider-events [get]
+func listProviderEvents(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	q := r.URL.Query()
+	status := q.Get("status")
+	switch status {
+	case "":
+		status = eventReview
+	case eventReview, eventResolved, eventProcessed, eventIgnored:
+	default:
+		httpx.WriteErr(w, http.StatusBadRequest, "status must be one of review, resolved, processed, ignored")
+		return
+	}
+	limit, err := pagination.ParseLimit(q.Get("limit"))
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var after int64
+	if v := q.Get("cursor"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			httpx.WriteErr(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		after = n
+	}
+
+	logger := slog.Default().With(
+		slog.String("component", "payments"),
+		slog.String("op", "listProviderEvents"),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+providerEventColumns+`
+		FROM provider_event
+		WHERE status = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3`,
+		status, after, limit+1,
+	)
+	if err != nil {
+		logger.Error("query provider events failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+
+	events := make([]ProviderEvent, 0)
+	for rows.Next() {
+		ev, err := scanProviderEvent(rows)
+		if err != nil {
+			logger.Error("scan provider event failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		events = append(events, ev)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("rows error after iteration", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	resp := ListProviderEventsResponse{Events: events}
+	if len(events) > limit {
+		next := strconv.FormatInt(events[limit-1].ID, 10)
+		resp.Events = events[:limit]
+		resp.NextCursor = &next
+	}
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+// getProviderEvent handles GET /api/provider-events/{eventID}
+//
+// @Summary      Get a provider event
+// @Description  Returns one provider event with its payload. Platform admins only.
+// @Tags         review
+// @Produce      json
+// @Param        eventID  path      int  true  "Provider event ID"
+// @Success      200      {object}  ProviderEvent
+// @Failure      403      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Router       /api/provider-events/{eventID} [get]
+func getProviderEvent(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	eventID, err := strconv.ParseInt(chi.URLParam(r, "eventID"), 10, 64)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusNotFound, "provider event not found")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	ev, err := scanProviderEvent(db.QueryRowContext(ctx, `SELECT `+providerEventColumns+` FROM provider_event WHERE id = $1`, eventID))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusNotFound, "provider event not found")
+		return
+	} else if err != nil {
+		slog.Error("query provider event failed", slog.Int64("event_id", eventID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, ev)
+}
+
+// resolveProviderEvent handles POST /api/provider-events/{eventID}/resolve
+//
+// @Summary      Resolve a review event
+// @Description  Takes an event out of the review queue once an operator has dealt with it, recording who did and how. Resolving does not change the order, payment or refund; fix those first. Platform admins only.
+// @Tags         review
+// @Accept       json
+// @Produce      json
+// @Param        eventID  path      int             true  "Provider event ID"
+// @Param        payload  body      ResolvePayload  true  "What was done"
+// @Success      200      {object}  ProviderEvent
+// @Failure      400      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse  "Event is not in the review queue"
+// @Router       /api/provider-events/{eventID}/resolve [post]
+func resolveProviderEvent(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	eventID, err := strconv.ParseInt(chi.URLParam(r, "eventID"), 10, 64)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusNotFound, "provider event not found")
+		return
+	}
+
+	defer r.Body.Close()
+	var p ResolvePayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		httpx.WriteBadRequest(w)
+		return
+	}
+	p.Note = strings.TrimSpace(p.Note)
+	if p.Note == "" {
+		httpx.WriteErr(w, http.StatusBadRequest, "note is required")
+		return
+	}
+
+	logger := slog.Default().With(
+		slog.String("component", "payments"),
+		slog.String("op", "resolveProviderEvent"),
+		slog.Int64("event_id", eventID),
+		slog.String("firebase_id", u.UID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	ev, err := scanProviderEvent(db.QueryRowContext(ctx, `
+		UPDATE provider_event
+		SET status = $2, resolved_at = now(), resolution_note = $3,
+			resolved_by = (SELECT id FROM "user" WHERE firebase_id = $4)
+		WHERE id = $1 AND status = $5
+		RETURNING `+providerEventColumns,
+		eventID, eventResolved, p.Note, u.UID, eventReview,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		var exists bool
+		if err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM provider_event WHERE id = $1)`, eventID).Scan(&exists); err != nil {
+			logger.Error("query provider event failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		if !exists {
+			httpx.WriteErr(w, http.StatusNotFound, "provider event not found")
+			return
+		}
+		httpx.WriteErr(w, http.StatusConflict, "provider event is not in the review queue")
+		return
+	} else if err != nil {
+		logger.Error("resolve provider event failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	logger.Info("provider event resolved")
+
+	httpx.WriteJSON(w, http.StatusOK, ev)
+}
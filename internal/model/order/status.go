@@ -0,0 +1,76 @@
+package order
+
+import "fmt"
+
+// Status is the lifecycle state of an order.
+//
+//	pending → paid → fulfilled
+//	pending → cancelled | expired
+//	paid, fulfilled → refunded | partially_refunded
+//	partially_refunded → partially_refunded | refunded
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusPaid      Status = "paid"
+	StatusFulfilled Status = "fulfilled"
+	StatusCancelled Status = "cancelled"
+	StatusRefunded  Status = "refunded"
+	StatusExpired   Status = "expired" // set by RunExpirer when an order stays unpaid too long
+
+	// StatusPartiallyRefunded may repeat: every further partial refund is recorded as a transition.
+	StatusPartiallyRefunded Status = "partially_refunded"
+)
+
+// transitions lists the legal next states for each state. Terminal states have no entry.
+var transitions = map[Status][]Status{
+	StatusPending:           {StatusPaid, StatusCancelled, StatusExpired},
+	StatusPaid:              {StatusFulfilled, StatusRefunded, StatusPartiallyRefunded},
+	StatusFulfilled:         {StatusRefunded, StatusPartiallyRefunded},
+	StatusPartiallyRefunded: {StatusPartiallyRefunded, StatusRefunded},
+}
+
+// valid reports whether s is a known status.
+func (s Status) valid() bool {
+	switch s {
+	case StatusPending, StatusPaid, StatusFulfilled, StatusCancelled, StatusRefunded, StatusPartiallyRefunded, StatusExpired:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo reports whether moving from s to next is allowed.
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionError is returned when a requested status change is not allowed.
+type TransitionError struct {
+	From Status
+	To   Status
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot move order from %s to %s", e.From, e.To)
+}
+
+// ActorKind identifies who caused a status change.
+type ActorKind string
+
+const (
+	ActorUser     ActorKind = "user"
+	ActorProvider ActorKind = "provider"
+	ActorSystem   ActorKind = "system"
+)
+
+// Actor is recorded in order_status_history for every transition.
+// UserID is the internal "user".id and is only set for ActorUser.
+type Actor struct {
+	Kind   ActorKind
+	UserID string
+}
@@ -0,0 +1,47 @@
+package order
+
+import "testing"
+
+var allStatuses = []Status{
+	StatusPending, StatusPaid, StatusFulfilled, StatusCancelled, StatusRefunded, StatusPartiallyRefunded, StatusExpired,
+}
+
+func TestCanTransitionTo(t *testing.T) {
+	legal := map[[2]Status]bool{
+		{StatusPending, StatusPaid}:                        true,
+		{StatusPending, StatusCancelled}:                   true,
+		{StatusPending, StatusExpired}:                     true,
+		{StatusPaid, StatusFulfilled}:                      true,
+		{StatusPaid, StatusRefunded}:                       true,
+		{StatusPaid, StatusPartiallyRefunded}:              true,
+		{StatusFulfilled, StatusRefunded}:                  true,
+		{StatusFulfilled, StatusPartiallyRefunded}:         true,
+		{StatusPartiallyRefunded, StatusPartiallyRefunded}: true,
+		{StatusPartiallyRefunded, StatusRefunded}:          true,
+	}
+	for _, from := range allStatuses {
+		for _, to := range allStatuses {
+			if got, want := from.CanTransitionTo(to), legal[[2]Status{from, to}]; got != want {
+				t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+	for _, s := range allStatuses {
+		if s.CanTransitionTo("unknown") || Status("unknown").CanTransitionTo(s) {
+			t.Errorf("unknown status transitions to or from %s", s)
+		}
+	}
+}
+
+func TestStatusValid(t *testing.T) {
+	for _, s := range allStatuses {
+		if !s.valid() {
+			t.Errorf("%s is not valid", s)
+		}
+	}
+	for _, s := range []Status{"", "unknown", "Paid"} {
+		if s.valid() {
+			t.Errorf("%q is valid", s)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+	"backend/internal/model/webhook"
+)
+
+// errOrderNotFound is returned by transition when the order does not exist.
+var errOrderNotFound = errors.New("order not found")
+
+// TransitionPayload is the optional body accepted by the transition endpoints.
+type TransitionPayload struct {
+	Note string `json:"note"`
+}
+
+// attachTransitionRoutes registers the status transition endpoints.
+// Orders become paid only through a captured payment (POST /{orderID}/payments or the payment
+// link), which also posts it to the ledger; expiry is driven by the system. Neither has an endpoint.
+func attachTransitionRoutes(r chi.Router, db *sql.DB) {
+	r.Post("/{orderID}/cancel", func(w http.ResponseWriter, r *http.Request) { transitionOrder(db, StatusCancelled, w, r) })
+	r.Post("/{orderID}/fulfill", func(w http.ResponseWriter, r *http.Request) { transitionOrder(db, StatusFulfilled, w, r) })
+}
+
+// transitionOrder handles POST /api/orders/{orderID}/{cancel,fulfill}
+//
+// @Summary      Change an order's status
+// @Description  Moves the order along its lifecycle (paid → fulfilled, pending → cancelled). Orders become paid through their payments. Illegal transitions are rejected with 409.
+// @Tags         orders
+// @Accept       json
+// @Produce      json
+// @Param        orderID  path      string             true   "Order ID"
+// @Param        payload  body      TransitionPayload  false  "Optional note recorded in the status history"
+// @Success      200      {object}  Order
+// @Failure      403      {object}  businessuser.MissingPermissionResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse  "Illegal status transition"
+// @Router       /api/orders/{orderID}/cancel [post]
+// @Router       /api/orders/{orderID}/fulfill [post]
+func transitionOrder(db *sql.DB, to Status, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p TransitionPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		httpx.WriteBadRequest(w)
+		return
+	}
+
+	orderID := chi.URLParam(r, "orderID")
+	logger := slog.Default().With(
+		slog.String("component", "orders"),
+		slog.String("op", "transitionOrder"),
+		slog.String("order_id", orderID),
+		slog.String("to", string(to)),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if _, ok := loadAuthorizedOrder(ctx, db, w, orderID, u, businessuser.PermOrdersUpdate); !ok {
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("begin tx failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var userID string
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM "user" WHERE firebase_id = $1`, u.UID).Scan(&userID); err != nil {
+		logger.Error("query user failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	ord, err := transition(ctx, tx, orderID, to, Actor{Kind: ActorUser, UserID: userID}, strings.TrimSpace(p.Note))
+	if !writeTransitionErr(w, logger, err) {
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("commit failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ord)
+}
+
+// writeTransitionErr maps a transition error to an HTTP response.
+// Returns true when err is nil and the caller may proceed.
+func writeTransitionErr(w http.ResponseWriter, logger *slog.Logger, err error) bool {
+	var te *TransitionError
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &te):
+		httpx.WriteErr(w, http.StatusConflict, te.Error())
+	case errors.Is(err, errOrderNotFound):
+		httpx.WriteErr(w, http.StatusNotFound, "order not found")
+	default:
+		logger.Error("order transition failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+	}
+	return false
+}
+
+// transition moves an order to a new status inside tx, records the change in order_status_history
+// and enqueues the matching webhook event.
+// The order row is locked for the rest of the transaction. It returns a *TransitionError for illegal moves.
+func transition(ctx context.Context, tx *sql.Tx, orderID string, to Status, actor Actor, note string) (Order, error) {
+	var from Status
+	err := tx.QueryRowContext(ctx, `SELECT status FROM "order" WHERE id = $1::uuid FOR UPDATE`, orderID).Scan(&from)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Order{}, errOrderNotFound
+	} else if err != nil {
+		return Order{}, err
+	}
+	if !from.CanTransitionTo(to) {
+		return Order{}, &TransitionError{From: from, To: to}
+	}
+
+	ord, err := scanOrder(tx.QueryRowContext(ctx, `
+		UPDATE "order" SET status = $2, updated_at = now()
+		WHERE id = $1::uuid
+		RETURNING `+orderColumns,
+		orderID, to,
+	))
+	if err != nil {
+		return Order{}, err
+	}
+
+	if err := recordStatus(ctx, tx, orderID, &from, to, actor, note); err != nil {
+		return Order{}, err
+	}
+	// Every status has a matching order.<status> webhook event type.
+	if err := webhook.Enqueue(ctx, tx, ord.BusinessID, webhook.EventType("order."+string(to)), ord); err != nil {
+		return Order{}, err
+	}
+	if to == StatusPaid {
+		if err := notifyPaid(ctx, tx, ord); err != nil {
+			return Order{}, err
+		}
+	}
+	return ord, nil
+}
+
+// recordStatus appends a row to order_status_history. from is nil for the creation entry.
+func recordStatus(ctx context.Context, tx *sql.Tx, orderID string, from *Status, to Status, actor Actor, note string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO order_status_history (order_id, from_status, to_status, actor_kind, actor_id, note)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		orderID, from, to, actor.Kind, nullIfEmpty(actor.UserID), nullIfEmpty(note),
+	)
+	return err
+}
@@ -0,0 +1,79 @@
+package order
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTransitionOrder(t *testing.T) {
+	env := newTestEnv(t)
+	biz := env.newBusiness(t, "BZD")
+	ownerID, owner := env.newMember(t, biz, "owner")
+	_, viewer := env.newMember(t, biz, "viewer")
+
+	tests := []struct {
+		name    string
+		from    Status
+		action  string
+		token   string
+		status  int
+		want    Status // the order's status afterwards
+		history bool   // whether a history row is written
+	}{
+		{"cancel pending", StatusPending, "cancel", owner, http.StatusOK, StatusCancelled, true},
+		{"fulfill paid", StatusPaid, "fulfill", owner, http.StatusOK, StatusFulfilled, true},
+		{"fulfill pending", StatusPending, "fulfill", owner, http.StatusConflict, StatusPending, false},
+		{"cancel paid", StatusPaid, "cancel", owner, http.StatusConflict, StatusPaid, false},
+		{"cancel cancelled", StatusCancelled, "cancel", owner, http.StatusConflict, StatusCancelled, false},
+		{"fulfill fulfilled", StatusFulfilled, "fulfill", owner, http.StatusConflict, StatusFulfilled, false},
+		{"cancel expired", StatusExpired, "cancel", owner, http.StatusConflict, StatusExpired, false},
+		{"fulfill refunded", StatusRefunded, "fulfill", owner, http.StatusConflict, StatusRefunded, false},
+		{"cancel partially refunded", StatusPartiallyRefunded, "cancel", owner, http.StatusConflict, StatusPartiallyRefunded, false},
+		{"cancel without permission", StatusPending, "cancel", viewer, http.StatusForbidden, StatusPending, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ord := env.newOrder(t, biz, ownerID, 1000, "BZD")
+			if tt.from != StatusPending {
+				env.setStatus(t, ord.ID, tt.from)
+			}
+			before := env.historyCount(t, ord.ID)
+
+			rec := env.do(t, tt.token, http.MethodPost, "/"+ord.ID+"/"+tt.action, `{"note":"test"}`)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if got := env.orderStatus(t, ord.ID); got != tt.want {
+				t.Fatalf("order status = %s, want %s", got, tt.want)
+			}
+			after := env.historyCount(t, ord.ID)
+			if tt.history && after != before+1 {
+				t.Fatalf("history rows = %d, want %d", after, before+1)
+			}
+			if !tt.history && after != before {
+				t.Fatalf("history rows = %d, want %d", after, before)
+			}
+			if tt.status == http.StatusOK {
+				if got := decode[Order](t, rec); got.Status != tt.want {
+					t.Fatalf("response status = %s, want %s", got.Status, tt.want)
+				}
+			}
+		})
+	}
+
+	t.Run("mark-paid is gone", func(t *testing.T) {
+		ord := env.newOrder(t, biz, ownerID, 1000, "BZD")
+		if rec := env.do(t, owner, http.MethodPost, "/"+ord.ID+"/mark-paid", ""); rec.Code != http.StatusNotFound && rec.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("status = %d, want 404 or 405", rec.Code)
+		}
+		if got := env.orderStatus(t, ord.ID); got != StatusPending {
+			t.Fatalf("order status = %s, want pending", got)
+		}
+	})
+
+	t.Run("unknown order", func(t *testing.T) {
+		if rec := env.do(t, owner, http.MethodPost, "/00000000-0000-0000-0000-000000000000/cancel", ""); rec.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want 404", rec.Code)
+		}
+	})
+}
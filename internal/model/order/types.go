@@ -1,6 +1,10 @@
 package order
 
-import "time"
+import (
+	"time"
+
+	"backend/internal/money"
+)
 
 type Order struct {
 	ID            string    `json:"id"`
@@ -8,9 +12,36 @@ type Order struct {
 	UpdatedAt     time.Time `json:"updated_at"`
 	BusinessID    string    `json:"business_id"`
 	CreatedBy     string    `json:"created_by"`
-	Status        string    `json:"status"`
-	Amount        float64   `json:"amount"`
+	CreatedByName *string   `json:"created_by_name,omitempty"` // creator's display name; only set when reading orders
+	Status        Status    `json:"status"`
+	Amount        string    `json:"amount"`       // decimal string, e.g. "12.50"
+	AmountMinor   int64     `json:"amount_minor"` // integer minor units, e.g. 1250
 	Currency      string    `json:"currency"`
 	Description   *string   `json:"description,omitempty"`
-	CustomerEmail *string   `json:"customer_email,omitempty"`
+	CustomerID    *string   `json:"customer_id,omitempty"`
+	CustomerEmail *string   `json:"customer_email,omitempty"` // the customer's current email
+
+	// PaymentLinkToken identifies the order's public payment link, /pay/{token}.
+	PaymentLinkToken     string    `json:"payment_link_token"`
+	PaymentLinkExpiresAt time.Time `json:"payment_link_expires_at"`
+}
+
+// orderColumns is the column list scanned by scanOrder. It works with or without the o alias.
+const orderColumns = `id, created_at, updated_at, business_id, created_by, status, amount_minor, currency, description,
+	customer_id, (SELECT cust.email FROM customer cust WHERE cust.id = customer_id), payment_link_token, payment_link_expires_at`
+
+// creatorNameColumn selects the creator's display name for an order aliased as o.
+const creatorNameColumn = `(SELECT NULLIF(concat_ws(' ', cu.name, cu.last_name), '') FROM "user" cu WHERE cu.id = o.created_by)`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanOrder scans orderColumns followed by any extra destinations.
+func scanOrder(row rowScanner, extra ...any) (Order, error) {
+	var o Order
+	dest := []any{&o.ID, &o.CreatedAt, &o.UpdatedAt, &o.BusinessID, &o.CreatedBy, &o.Status, &o.AmountMinor, &o.Currency, &o.Description, &o.CustomerID, &o.CustomerEmail, &o.PaymentLinkToken, &o.PaymentLinkExpiresAt}
+	err := row.Scan(append(dest, extra...)...)
+	o.Amount = money.FromMinor(o.AmountMinor, o.Currency).String()
+	return o, err
 }
@@ -0,0 +1,215 @@
+package payout
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+	"backend/internal/model/ledger"
+)
+
+// errNothingToPay is returned by payOut when the unpaid available funds do not add up to a positive amount.
+var errNothingToPay = errors.New("no funds available for payout")
+
+// PayoutPayload is the body accepted by POST /api/payouts.
+// Currency defaults to the business's default currency.
+type PayoutPayload struct {
+	BusinessID string `json:"business_id"`
+	Currency   string `json:"currency" example:"BZD"`
+}
+
+// attachCreateRoutes registers the manual payout (POST) endpoint.
+func attachCreateRoutes(r chi.Router, db *sql.DB) {
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) { createPayout(db, w, r) })
+}
+
+// createPayout handles POST /api/payouts
+//
+// @Summary      Request a payout
+// @Description  Pays out the business's available funds in one currency now, regardless of its payout schedule. Requires the business:manage permission.
+// @Tags         payouts
+// @Accept       json
+// @Produce      json
+// @Param        Idempotency-Key  header    string         false  "Makes the request safe to retry"
+// @Param        payload          body      PayoutPayload  true   "Business and currency"
+// @Success      201              {object}  Payout
+// @Failure      400              {object}  ErrorResponse
+// @Failure      403              {object}  businessuser.MissingPermissionResponse
+// @Failure      409              {object}  ErrorResponse  "No funds available for payout"
+// @Router       /api/payouts [post]
+func createPayout(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p PayoutPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		httpx.WriteBadRequest(w)
+		return
+	}
+	p.BusinessID = strings.TrimSpace(p.BusinessID)
+	if p.BusinessID == "" {
+		httpx.WriteErr(w, http.StatusBadRequest, "business_id is required")
+		return
+	}
+
+	logger := slog.Default().With(
+		slog.String("component", "payouts"),
+		slog.String("op", "createPayout"),
+		slog.String("business_id", p.BusinessID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if !businessuser.AssertPermission(ctx, db, w, p.BusinessID, u, businessuser.PermBusinessManage) {
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Error("begin tx failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Locking the business serializes its payouts.
+	var userID, defCurrency string
+	if err := tx.QueryRowContext(ctx, `
+		SELECT (SELECT id FROM "user" WHERE firebase_id = $2), default_currency
+		FROM business WHERE id = $1::uuid
+		FOR UPDATE`,
+		p.BusinessID, u.UID,
+	).Scan(&userID, &defCurrency); err != nil {
+		logger.Error("lock business failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	currency := strings.ToUpper(strings.TrimSpace(p.Currency))
+	if currency == "" {
+		currency = defCurrency
+	}
+
+	po, err := payOut(ctx, tx, p.BusinessID, currency, TriggerManual, userID)
+	if errors.Is(err, errNothingToPay) {
+		httpx.WriteErr(w, http.StatusConflict, "no "+currency+" funds available for payout")
+		return
+	} else if err != nil {
+		logger.Error("create payout failed", slog.String("currency", currency), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Error("commit failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusCreated, po)
+}
+
+// payOut pays every unpaid movement of the business's available funds in currency out as one
+// payout and posts it to the ledger, inside tx. The caller must hold the business row lock.
+// Returns errNothingToPay when the movements do not add up to a positive amount; they are then
+// left for a later payout.
+func payOut(ctx context.Context, tx *sql.Tx, businessID, currency string, trigger Trigger, createdBy string) (Payout, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT t.id, t.kind, t.order_id, t.payment_id, t.refund_id, e.amount_minor, p.amount_minor, p.fee_minor
+		FROM ledger_transaction t
+		JOIN ledger_entry e ON e.transaction_id = t.id
+		JOIN ledger_account a ON a.id = e.account_id AND a.code = $3
+		JOIN payment p ON p.id = t.payment_id
+		WHERE t.business_id = $1 AND t.currency = $2 AND t.kind IN ($4, $5)
+			AND NOT EXISTS (SELECT 1 FROM payout_item i WHERE i.ledger_transaction_id = t.id)
+		ORDER BY t.posted_at, t.id`,
+		businessID, currency, ledger.AccountAvailable, ledger.KindSettlement, ledger.KindRefund,
+	)
+	if err != nil {
+		return Payout{}, err
+	}
+
+	type line struct {
+		txnID, orderID, paymentID string
+		refundID                  *string
+		gross, fee, refunded, net int64
+	}
+	var lines []line
+	var gross, fee, refunded, net int64
+	for rows.Next() {
+		var l line
+		var kind ledger.Kind
+		var paymentAmount, paymentFee int64
+		if err := rows.Scan(&l.txnID, &kind, &l.orderID, &l.paymentID, &l.refundID, &l.net, &paymentAmount, &paymentFee); err != nil {
+			rows.Close()
+			return Payout{}, err
+		}
+		if kind == ledger.KindSettlement {
+			l.gross, l.fee = paymentAmount, paymentFee
+			l.refunded = l.gross - l.fee - l.net
+		} else {
+			l.refunded = -l.net
+		}
+		gross, fee, refunded, net = gross+l.gross, fee+l.fee, refunded+l.refunded, net+l.net
+		lines = append(lines, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return Payout{}, err
+	}
+	if net <= 0 {
+		return Payout{}, errNothingToPay
+	}
+
+	po, err := scanPayout(tx.QueryRowContext(ctx, `
+		INSERT INTO payout (business_id, currency, trigger, gross_minor, fee_minor, refunded_minor, amount_minor, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+payoutColumns,
+		businessID, currency, trigger, gross, fee, refunded, net, nullIfEmpty(createdBy),
+	))
+	if err != nil {
+		return Payout{}, err
+	}
+
+	for _, l := range lines {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO payout_item (payout_id, ledger_transaction_id, order_id, payment_id, refund_id,
+				gross_minor, fee_minor, refunded_minor, amount_minor)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			po.ID, l.txnID, l.orderID, l.paymentID, l.refundID, l.gross, l.fee, l.refunded, l.net,
+		); err != nil {
+			return Payout{}, err
+		}
+	}
+
+	err = ledger.Post(ctx, tx, ledger.Transaction{
+		BusinessID:  businessID,
+		Kind:        ledger.KindPayout,
+		Currency:    currency,
+		PayoutID:    po.ID,
+		Description: "payout " + po.ID,
+		Entries:     ledger.Transfer(ledger.AccountPayouts, ledger.AccountAvailable, net),
+	})
+	return po, err
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
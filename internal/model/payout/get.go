@@ -0,0 +1,234 @@
+package payout
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+	"backend/internal/pagination"
+)
+
+// ListPayoutsResponse is one page of payouts. NextCursor is null on the last page.
+type ListPayoutsResponse struct {
+	Payouts    []Payout `json:"payouts"`
+	NextCursor *string  `json:"next_cursor"`
+}
+
+// attachGetRoutes registers the list, single-payout and item (GET) endpoints.
+func attachGetRoutes(r chi.Router, db *sql.DB) {
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) { listPayouts(db, w, r) })
+	r.Get("/{payoutID}", func(w http.ResponseWriter, r *http.Request) { getPayout(db, w, r) })
+	r.Get("/{payoutID}/items", func(w http.ResponseWriter, r *http.Request) { getPayoutItems(db, w, r) })
+}
+
+// listPayouts handles GET /api/payouts?business_id=...
+//
+// @Summary      List payouts
+// @Description  Returns a page of the business's payouts, newest first. Requires the finance:read permission. If business_id is omitted and the authenticated user belongs to exactly one business, that business is used. Pass next_cursor back as cursor to fetch the following page.
+// @Tags         payouts
+// @Produce      json
+// @Param        business_id  query     string  false  "Business ID"
+// @Param        limit        query     int     false  "Page size (1-200, default 50)"
+// @Param        cursor       query     string  false  "next_cursor from the previous page"
+// @Success      200          {object}  ListPayoutsResponse
+// @Failure      400          {object}  businessuser.AmbiguousBusinessResponse
+// @Failure      403          {object}  businessuser.MissingPermissionResponse
+// @Router       /api/payouts [get]
+func listPayouts(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	limit, after, err := pagination.Parse(q, false)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var afterAt *time.Time
+	var afterID *string
+	if after != nil {
+		afterAt, afterID = &after.CreatedAt, &after.ID
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	bizID, ok := businessuser.ResolveBusinessID(ctx, db, w, q.Get("business_id"), u)
+	if !ok {
+		return
+	}
+	if !businessuser.AssertPermission(ctx, db, w, bizID, u, businessuser.PermFinanceRead) {
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+payoutColumns+`
+		FROM payout
+		WHERE business_id = $1::uuid
+			AND ($2::timestamptz IS NULL OR (created_at, id) < ($2, $3::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4`,
+		bizID, afterAt, afterID, limit+1,
+	)
+	if err != nil {
+		slog.Error("query payouts failed", slog.String("business_id", bizID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+
+	payouts := make([]Payout, 0)
+	for rows.Next() {
+		po, err := scanPayout(rows)
+		if err != nil {
+			slog.Error("scan payout row failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		payouts = append(payouts, po)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error("rows error after iteration", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	resp := ListPayoutsResponse{Payouts: payouts}
+	if len(payouts) > limit {
+		last := payouts[limit-1]
+		next := pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+		resp.Payouts = payouts[:limit]
+		resp.NextCursor = &next
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// getPayout handles GET /api/payouts/{payoutID}
+//
+// @Summary      Get a payout
+// @Tags         payouts
+// @Produce      json
+// @Param        payoutID  path      string  true  "Payout ID"
+// @Success      200       {object}  Payout
+// @Failure      403       {object}  businessuser.MissingPermissionResponse
+// @Failure      404       {object}  ErrorResponse
+// @Router       /api/payouts/{payoutID} [get]
+func getPayout(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	po, ok := loadPayout(ctx, db, w, chi.URLParam(r, "payoutID"), u)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(po)
+}
+
+// getPayoutItems handles GET /api/payouts/{payoutID}/items
+//
+// @Summary      List a payout's items
+// @Description  Returns the orders included in the payout: one line per settled payment, net of its fee and of refunds made before it settled, and one negative line per refund made after its payment settled.
+// @Tags         payouts
+// @Produce      json
+// @Param        payoutID  path      string  true  "Payout ID"
+// @Success      200       {array}   Item
+// @Failure      403       {object}  businessuser.MissingPermissionResponse
+// @Failure      404       {object}  ErrorResponse
+// @Router       /api/payouts/{payoutID}/items [get]
+func getPayoutItems(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	po, ok := loadPayout(ctx, db, w, chi.URLParam(r, "payoutID"), u)
+	if !ok {
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+itemColumns+`
+		FROM payout_item i
+		JOIN payout p ON p.id = i.payout_id
+		JOIN "order" o ON o.id = i.order_id
+		WHERE i.payout_id = $1
+		ORDER BY i.id`,
+		po.ID,
+	)
+	if err != nil {
+		slog.Error("query payout items failed", slog.String("payout_id", po.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+
+	items := make([]Item, 0)
+	for rows.Next() {
+		it, err := scanItem(rows)
+		if err != nil {
+			slog.Error("scan payout item row failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error("rows error after iteration", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(items)
+}
+
+// loadPayout fetches a payout and checks the caller may read its business's finances.
+// Returns false after writing 404, 403 or 500.
+func loadPayout(ctx context.Context, db *sql.DB, w http.ResponseWriter, payoutID string, u *auth.User) (Payout, bool) {
+	if _, err := uuid.Parse(payoutID); err != nil {
+		httpx.WriteErr(w, http.StatusNotFound, "payout not found")
+		return Payout{}, false
+	}
+	po, err := scanPayout(db.QueryRowContext(ctx,
+		`SELECT `+payoutColumns+` FROM payout WHERE id = $1`, payoutID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusNotFound, "payout not found")
+		return Payout{}, false
+	} else if err != nil {
+		slog.Error("query payout failed", slog.String("payout_id", payoutID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Payout{}, false
+	}
+	if !businessuser.AssertPermission(ctx, db, w, po.BusinessID, u, businessuser.PermFinanceRead) {
+		return Payout{}, false
+	}
+	return po, true
+}
@@ -0,0 +1,16 @@
+package payout
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Routes aggregates all payout submodule routes (list, detail, items, manual payouts).
+func Routes(db *sql.DB) http.Handler {
+	r := chi.NewRouter()
+	attachCreateRoutes(r, db)
+	attachGetRoutes(r, db)
+	return r
+}
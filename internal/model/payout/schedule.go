@@ -0,0 +1,122 @@
+package payout
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// RunScheduler settles captured payments once settlementDelay has passed and creates the payouts
+// due under each business's schedule, every interval until ctx is cancelled. Daily payouts are
+// made once per UTC day and weekly ones once per week starting Monday. Several replicas may run
+// it concurrently.
+func RunScheduler(ctx context.Context, db *sql.DB, interval, settlementDelay time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if n, err := settleDue(ctx, db, settlementDelay.Seconds()); err != nil {
+				slog.Warn("settle payments failed", slog.Any("err", err))
+			} else if n > 0 {
+				slog.Info("payments settled", slog.Int("count", n))
+			}
+			if err := payOutScheduled(ctx, db); err != nil {
+				slog.Warn("scheduled payouts failed", slog.Any("err", err))
+			}
+		}
+	}
+}
+
+// scheduleDueSQL holds for a business b whose schedule has no payout in currency cur yet in
+// the current period.
+const scheduleDueSQL = `b.payout_schedule <> 'manual' AND NOT EXISTS (
+	SELECT 1 FROM payout p
+	WHERE p.business_id = b.id AND p.currency = cur AND p.trigger = 'schedule'
+		AND p.created_at >= date_trunc(CASE b.payout_schedule WHEN 'weekly' THEN 'week' ELSE 'day' END, now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'
+)`
+
+// payOutScheduled creates a payout for every business and currency that is due and has unpaid
+// available funds.
+func payOutScheduled(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT b.id, c.cur
+		FROM business b
+		JOIN LATERAL (
+			SELECT DISTINCT t.currency AS cur
+			FROM ledger_transaction t
+			JOIN ledger_entry e ON e.transaction_id = t.id
+			JOIN ledger_account a ON a.id = e.account_id AND a.code = 'available'
+			WHERE t.business_id = b.id AND t.kind IN ('settlement', 'refund')
+				AND NOT EXISTS (SELECT 1 FROM payout_item i WHERE i.ledger_transaction_id = t.id)
+		) c ON true
+		WHERE `+scheduleDueSQL,
+	)
+	if err != nil {
+		return err
+	}
+	type due struct{ businessID, currency string }
+	var pending []due
+	for rows.Next() {
+		var d due
+		if err := rows.Scan(&d.businessID, &d.currency); err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, d := range pending {
+		po, err := payOutIfDue(ctx, db, d.businessID, d.currency)
+		switch {
+		case errors.Is(err, errNothingToPay):
+		case err != nil:
+			slog.Warn("scheduled payout failed", slog.String("business_id", d.businessID), slog.String("currency", d.currency), slog.Any("err", err))
+		case po != nil:
+			slog.Info("scheduled payout created", slog.String("business_id", d.businessID), slog.String("payout_id", po.ID),
+				slog.String("amount", po.Amount), slog.String("currency", po.Currency))
+		}
+	}
+	return nil
+}
+
+// payOutIfDue re-checks the schedule under the business row lock, so concurrent schedulers
+// create at most one payout per period. It returns nil when another one got there first.
+func payOutIfDue(ctx context.Context, db *sql.DB, businessID, currency string) (*Payout, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var isDue bool
+	if err := tx.QueryRowContext(ctx, `
+		SELECT `+scheduleDueSQL+`
+		FROM business b, (SELECT $2::text AS cur) c
+		WHERE b.id = $1
+		FOR UPDATE OF b`,
+		businessID, currency,
+	).Scan(&isDue); err != nil {
+		return nil, err
+	}
+	if !isDue {
+		return nil, nil
+	}
+
+	po, err := payOut(ctx, tx, businessID, currency, TriggerSchedule, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &po, nil
+}
@@ -0,0 +1,103 @@
+package payout
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"backend/internal/model/ledger"
+)
+
+// settleBatchSize bounds how many payments one settlement transaction handles.
+const settleBatchSize = 100
+
+// settleDue moves the net amount of payments captured at least delay ago from pending to
+// available, one batch per transaction. It returns how many payments were settled.
+func settleDue(ctx context.Context, db *sql.DB, delaySecs float64) (int, error) {
+	total := 0
+	for {
+		n, err := settleBatch(ctx, db, delaySecs)
+		total += n
+		if err != nil || n < settleBatchSize {
+			return total, err
+		}
+	}
+}
+
+type unsettled struct {
+	id          string
+	orderID     string
+	businessID  string
+	currency    string
+	amountMinor int64
+	feeMinor    int64
+}
+
+func settleBatch(ctx context.Context, db *sql.DB, delaySecs float64) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE payment SET settled_at = now()
+		WHERE id IN (
+			SELECT id FROM payment
+			WHERE status = 'succeeded' AND settled_at IS NULL
+				AND captured_at <= now() - make_interval(secs => $1)
+			ORDER BY captured_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, order_id, business_id, currency, amount_minor, fee_minor`,
+		delaySecs, settleBatchSize,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var batch []unsettled
+	for rows.Next() {
+		var p unsettled
+		if err := rows.Scan(&p.id, &p.orderID, &p.businessID, &p.currency, &p.amountMinor, &p.feeMinor); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch = append(batch, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, p := range batch {
+		// Refunds already paid out of pending reduce what becomes available; later ones are
+		// paid out of available. The payment row is locked, so none completes in between.
+		var refunded int64
+		if err := tx.QueryRowContext(ctx,
+			`SELECT COALESCE(sum(amount_minor), 0) FROM refund WHERE payment_id = $1 AND status = 'succeeded'`, p.id,
+		).Scan(&refunded); err != nil {
+			return 0, err
+		}
+		net := p.amountMinor - p.feeMinor - refunded
+		if net == 0 {
+			continue
+		}
+		if err := ledger.Post(ctx, tx, ledger.Transaction{
+			BusinessID:  p.businessID,
+			Kind:        ledger.KindSettlement,
+			Currency:    p.currency,
+			OrderID:     p.orderID,
+			PaymentID:   p.id,
+			Description: "settlement of payment " + p.id,
+			Entries:     ledger.Transfer(ledger.AccountAvailable, ledger.AccountPending, net),
+		}); err != nil {
+			return 0, fmt.Errorf("post settlement of payment %s: %w", p.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
@@ -0,0 +1,88 @@
+package payout
+
+import (
+	"time"
+
+	"backend/internal/money"
+)
+
+// Trigger is what created a payout.
+type Trigger string
+
+const (
+	TriggerSchedule Trigger = "schedule" // the business's daily or weekly schedule
+	TriggerManual   Trigger = "manual"   // a member requested it through the API
+)
+
+// Payout is one transfer of available funds to a business. Amount is Gross - Fee - Refunded
+// over its items.
+type Payout struct {
+	ID            string    `json:"id"`
+	BusinessID    string    `json:"business_id"`
+	Currency      string    `json:"currency"`
+	Trigger       Trigger   `json:"trigger"`
+	Gross         string    `json:"gross"`
+	GrossMinor    int64     `json:"gross_minor"`
+	Fee           string    `json:"fee"`
+	FeeMinor      int64     `json:"fee_minor"`
+	Refunded      string    `json:"refunded"`
+	RefundedMinor int64     `json:"refunded_minor"`
+	Amount        string    `json:"amount"`
+	AmountMinor   int64     `json:"amount_minor"`
+	CreatedBy     *string   `json:"created_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Item is one line of a payout: a settled payment net of its fee and of refunds made before
+// it settled, or a refund made after its payment settled (with a negative amount).
+type Item struct {
+	ID               int64     `json:"id"`
+	OrderID          string    `json:"order_id"`
+	OrderDescription *string   `json:"order_description,omitempty"`
+	OrderCreatedAt   time.Time `json:"order_created_at"`
+	PaymentID        string    `json:"payment_id"`
+	RefundID         *string   `json:"refund_id,omitempty"`
+	Gross            string    `json:"gross"`
+	GrossMinor       int64     `json:"gross_minor"`
+	Fee              string    `json:"fee"`
+	FeeMinor         int64     `json:"fee_minor"`
+	Refunded         string    `json:"refunded"`
+	RefundedMinor    int64     `json:"refunded_minor"`
+	Amount           string    `json:"amount"`
+	AmountMinor      int64     `json:"amount_minor"`
+}
+
+// payoutColumns is the column list scanned by scanPayout.
+const payoutColumns = `id, business_id, currency, trigger, gross_minor, fee_minor, refunded_minor, amount_minor, created_by, created_at`
+
+// itemColumns is the column list scanned by scanItem, for payout_item aliased as i,
+// "order" as o and payout as p.
+const itemColumns = `i.id, i.order_id, o.description, o.created_at, i.payment_id, i.refund_id,
+	i.gross_minor, i.fee_minor, i.refunded_minor, i.amount_minor, p.currency`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPayout(row rowScanner) (Payout, error) {
+	var p Payout
+	err := row.Scan(&p.ID, &p.BusinessID, &p.Currency, &p.Trigger, &p.GrossMinor, &p.FeeMinor, &p.RefundedMinor,
+		&p.AmountMinor, &p.CreatedBy, &p.CreatedAt)
+	p.Gross = money.FromMinor(p.GrossMinor, p.Currency).String()
+	p.Fee = money.FromMinor(p.FeeMinor, p.Currency).String()
+	p.Refunded = money.FromMinor(p.RefundedMinor, p.Currency).String()
+	p.Amount = money.FromMinor(p.AmountMinor, p.Currency).String()
+	return p, err
+}
+
+func scanItem(row rowScanner) (Item, error) {
+	var it Item
+	var currency string
+	err := row.Scan(&it.ID, &it.OrderID, &it.OrderDescription, &it.OrderCreatedAt, &it.PaymentID, &it.RefundID,
+		&it.GrossMinor, &it.FeeMinor, &it.RefundedMinor, &it.AmountMinor, &currency)
+	it.Gross = money.FromMinor(it.GrossMinor, currency).String()
+	it.Fee = money.FromMinor(it.FeeMinor, currency).String()
+	it.Refunded = money.FromMinor(it.RefundedMinor, currency).String()
+	it.Amount = money.FromMinor(it.AmountMinor, currency).String()
+	return it, err
+}
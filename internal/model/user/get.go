@@ -30,6 +30,7 @@ type GetUserResponse struct {
 type BusinessRecord struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
+	Role string `json:"role"`
 }
 
 // getUser returns profile + businesses for the Firebase-authenticated principal.
@@ -61,10 +62,10 @@ func getUser(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 
 	// Fetch associated businesses
 	bizRows, err := db.QueryContext(ctx, `
-		SELECT b.id, b.name
+		SELECT b.id, b.name, bu.role
 		FROM business_user bu
 		JOIN business b ON b.id = bu.business_id
-		WHERE bu.user_id = $1
+		WHERE bu.user_id = $1 AND b.archived_at IS NULL
 		ORDER BY b.created_at DESC
 	`, userID)
 	if err != nil {
@@ -77,7 +78,7 @@ func getUser(db *sql.DB, w http.ResponseWriter, r *http.Request) {
 	var businesses []BusinessRecord
 	for bizRows.Next() {
 		var b BusinessRecord
-		if err := bizRows.Scan(&b.ID, &b.Name); err != nil {
+		if err := bizRows.Scan(&b.ID, &b.Name, &b.Role); err != nil {
 			slog.Error("scan business row failed", slog.Any("err", err))
 			httpx.WriteInternalServerError(w)
 			return
@@ -4,21 +4,25 @@ import (
 	"database/sql"
 	"net/http"
 
-	firebaseauth "firebase.google.com/go/v4/auth"
 	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
 )
 
 // Routes exposes both public and private user endpoints under one router.
 // Public endpoints (e.g., registration) are mounted without middleware.
 // Private endpoints (e.g., get profile) are mounted with the provided middleware.
-func Routes(db *sql.DB, fbAuth *firebaseauth.Client, mw func(http.Handler) http.Handler) http.Handler {
+// accounts creates the sign-in account of newly registered users; sessions revokes sign-ins and
+// is nil when the auth provider cannot revoke them.
+func Routes(db *sql.DB, accounts auth.AccountCreator, sessions auth.SessionRevoker, mw func(http.Handler) http.Handler) http.Handler {
 	r := chi.NewRouter()
 
 	// Public
-	attachRegisterRoutes(r, db, fbAuth)
+	attachRegisterRoutes(r, db, accounts)
 
 	// Private (apply middleware to the subrouter passed into attachGetRoutes)
 	attachGetRoutes(r.With(mw), db)
+	attachSessionRoutes(r.With(mw), db, sessions)
 
 	return r
 }
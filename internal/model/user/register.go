@@ -8,15 +8,15 @@ import (
 	"strings"
 	"time"
 
+	"backend/internal/auth"
 	"backend/internal/httpx"
 
-	firebaseauth "firebase.google.com/go/v4/auth"
 	"github.com/go-chi/chi/v5"
 )
 
 // attachRegisterRoutes registers register (POST) endpoint(s).
-func attachRegisterRoutes(r chi.Router, db *sql.DB, fbAuth *firebaseauth.Client) {
-	r.Post("/", func(w http.ResponseWriter, r *http.Request) { registerUser(db, w, r, fbAuth) })
+func attachRegisterRoutes(r chi.Router, db *sql.DB, accounts auth.AccountCreator) {
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) { registerUser(db, w, r, accounts) })
 }
 
 type registerInput struct {
@@ -34,8 +34,8 @@ type registerResponse struct {
 }
 
 // registerUser creates (or idempotently upserts) a user row
-// and creates a Firebase user.
-func registerUser(db *sql.DB, w http.ResponseWriter, r *http.Request, fbAuth *firebaseauth.Client) {
+// and creates the sign-in account (a Firebase user unless running with local accounts).
+func registerUser(db *sql.DB, w http.ResponseWriter, r *http.Request, accounts auth.AccountCreator) {
 	var in registerInput
 	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
 		httpx.WriteErr(w, http.StatusBadRequest, "invalid JSON body")
@@ -50,11 +50,11 @@ func registerUser(db *sql.DB, w http.ResponseWriter, r *http.Request, fbAuth *fi
 		return
 	}
 
-	fbCtx, fbCancel := context.WithTimeout(r.Context(), 5*time.Second)
-	defer fbCancel()
-	fbUser, err := createFirebaseUser(fbCtx, fbAuth, in.Email, in.Password)
+	acctCtx, acctCancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer acctCancel()
+	uid, err := accounts.CreateAccount(acctCtx, in.Email, in.Password)
 	if err != nil {
-		httpx.WriteErr(w, http.StatusInternalServerError, "firebase: "+err.Error())
+		httpx.WriteErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -64,10 +64,10 @@ func registerUser(db *sql.DB, w http.ResponseWriter, r *http.Request, fbAuth *fi
 
 	var id string
 	row := db.QueryRowContext(ctx, `
-			INSERT INTO "user" (firebase_id, name, last_name)
-			VALUES ($1, $2, $3)
+			INSERT INTO "user" (firebase_id, name, last_name, email)
+			VALUES ($1, $2, $3, $4)
 			RETURNING id
-		`, fbUser.UID, in.Name, in.LastName)
+		`, uid, in.Name, in.LastName, strings.ToLower(in.Email))
 	if err := row.Scan(&id); err != nil {
 		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
 		return
@@ -76,20 +76,8 @@ func registerUser(db *sql.DB, w http.ResponseWriter, r *http.Request, fbAuth *fi
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(registerResponse{
 		ID:         id,
-		FirebaseID: fbUser.UID,
+		FirebaseID: uid,
 		Name:       in.Name,
 		LastName:   in.LastName,
 	})
 }
-
-// createFirebaseUser returns a user or an error; no HTTP writes inside.
-func createFirebaseUser(ctx context.Context, fbAuth *firebaseauth.Client, email string, password string) (*firebaseauth.UserRecord, error) {
-	params := (&firebaseauth.UserToCreate{}).
-		Email(email).
-		Password(password)
-	user, err := fbAuth.CreateUser(ctx, params)
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
-}
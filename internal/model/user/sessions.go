@@ -0,0 +1,115 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+)
+
+// RevokeSessionsPayload is the optional body of POST /api/user/sessions/revoke.
+type RevokeSessionsPayload struct {
+	// UserID is the internal ID of the user to sign out; it defaults to the caller.
+	UserID string `json:"user_id"`
+}
+
+// attachSessionRoutes registers the session endpoints.
+func attachSessionRoutes(r chi.Router, db *sql.DB, sessions auth.SessionRevoker) {
+	r.Post("/sessions/revoke", func(w http.ResponseWriter, r *http.Request) { revokeSessions(db, sessions, w, r) })
+}
+
+// revokeSessions handles POST /api/user/sessions/revoke
+//
+// @Summary      Sign out everywhere
+// @Description  Revokes every refresh token of the caller, or of user_id for platform admins (custom claim admin: true). Devices must sign in again once their current ID token expires, or immediately on requests that check revocation.
+// @Tags         user
+// @Accept       json
+// @Param        payload  body  RevokeSessionsPayload  false  "User to sign out"
+// @Success      204
+// @Failure      400  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse  "user_id not found (platform admins only)"
+// @Failure      501  {object}  ErrorResponse  "The auth provider cannot revoke sessions"
+// @Failure      502  {object}  ErrorResponse  "Auth provider error"
+// @Router       /api/user/sessions/revoke [post]
+func revokeSessions(db *sql.DB, sessions auth.SessionRevoker, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p RevokeSessionsPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		httpx.WriteBadRequest(w)
+		return
+	}
+	p.UserID = strings.TrimSpace(p.UserID)
+
+	if sessions == nil {
+		httpx.WriteErr(w, http.StatusNotImplemented, "the configured auth provider cannot revoke sessions")
+		return
+	}
+
+	logger := slog.Default().With(
+		slog.String("component", "user"),
+		slog.String("op", "revokeSessions"),
+		slog.String("firebase_id", u.UID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	target := u.UID
+	if p.UserID != "" {
+		if _, err := uuid.Parse(p.UserID); err != nil {
+			httpx.WriteErr(w, http.StatusBadRequest, "user_id must be a UUID")
+			return
+		}
+		if !auth.IsPlatformAdmin(u) {
+			// Others may only name themselves; whether another user exists is not revealed.
+			var self bool
+			err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM "user" WHERE id = $1 AND firebase_id = $2)`, p.UserID, u.UID).Scan(&self)
+			if err != nil {
+				logger.Error("query user failed", slog.Any("err", err))
+				httpx.WriteInternalServerError(w)
+				return
+			}
+			if !self {
+				httpx.WriteForbidden(w)
+				return
+			}
+		} else {
+			err := db.QueryRowContext(ctx, `SELECT firebase_id FROM "user" WHERE id = $1`, p.UserID).Scan(&target)
+			if errors.Is(err, sql.ErrNoRows) {
+				httpx.WriteErr(w, http.StatusNotFound, "user not found")
+				return
+			} else if err != nil {
+				logger.Error("query user failed", slog.Any("err", err))
+				httpx.WriteInternalServerError(w)
+				return
+			}
+		}
+	}
+
+	if err := sessions.RevokeSessions(ctx, target); err != nil {
+		logger.Error("revoke sessions failed", slog.String("target", target), slog.Any("err", err))
+		httpx.WriteErr(w, http.StatusBadGateway, "auth provider unavailable")
+		return
+	}
+	logger.Info("sessions revoked", slog.String("target", target))
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,138 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/business"
+	"backend/internal/model/businessuser"
+)
+
+// EndpointPayload is the body accepted by POST /api/webhooks.
+type EndpointPayload struct {
+	BusinessID  string      `json:"business_id"`
+	URL         string      `json:"url" example:"https://example.com/payway/webhook"`
+	Description string      `json:"description"`
+	EventTypes  []EventType `json:"event_types" example:"order.created,order.paid"`
+}
+
+// attachCreateRoutes registers the create (POST) endpoint.
+func attachCreateRoutes(r chi.Router, db *sql.DB, allowInsecure bool) {
+	r.Post("/", func(w http.ResponseWriter, r *http.Request) { createEndpoint(db, allowInsecure, w, r) })
+}
+
+// createEndpoint handles POST /api/webhooks
+//
+// @Summary      Register a webhook endpoint
+// @Description  Subscribes a URL to event types of a business. The URL must use https and resolve to a public address. Deliveries are signed with the returned secret, which is only shown once.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      EndpointPayload  true  "Endpoint payload"
+// @Success      201      {object}  EndpointWithSecret
+// @Failure      400      {object}  ErrorResponse
+// @Failure      403      {object}  businessuser.MissingPermissionResponse
+// @Failure      409      {object}  ErrorResponse  "Business is archived"
+// @Router       /api/webhooks [post]
+func createEndpoint(db *sql.DB, allowInsecure bool, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p EndpointPayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		httpx.WriteBadRequest(w)
+		return
+	}
+	p.BusinessID = strings.TrimSpace(p.BusinessID)
+	if p.BusinessID == "" {
+		httpx.WriteErr(w, http.StatusBadRequest, "business_id is required")
+		return
+	}
+	types, err := validateEventTypes(p.EventTypes)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger := slog.Default().With(
+		slog.String("component", "webhooks"),
+		slog.String("op", "createEndpoint"),
+		slog.String("business_id", p.BusinessID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	endpointURL, err := validateURL(ctx, p.URL, allowInsecure)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !businessuser.AssertPermission(ctx, db, w, p.BusinessID, u, businessuser.PermBusinessManage) {
+		return
+	}
+	if !business.AssertActive(ctx, db, w, p.BusinessID) {
+		return
+	}
+
+	secret := newSecret()
+	ep, err := scanEndpoint(db.QueryRowContext(ctx, `
+		INSERT INTO webhook_endpoint (business_id, url, description, secret, event_types, created_by)
+		VALUES ($1, $2, $3, $4, $5::jsonb, (SELECT id FROM "user" WHERE firebase_id = $6))
+		RETURNING `+endpointColumns,
+		p.BusinessID, endpointURL, nullIfEmpty(strings.TrimSpace(p.Description)), secret, types, u.UID,
+	))
+	if err != nil {
+		logger.Error("insert webhook endpoint failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusCreated, EndpointWithSecret{Endpoint: ep, Secret: secret})
+}
+
+// validateEventTypes checks every type is known and returns the deduplicated list as a JSON array.
+func validateEventTypes(types []EventType) (string, error) {
+	if len(types) == 0 {
+		return "", errors.New("event_types must not be empty")
+	}
+	seen := make(map[EventType]bool, len(types))
+	out := make([]EventType, 0, len(types))
+	for _, t := range types {
+		t = EventType(strings.TrimSpace(string(t)))
+		if !t.valid() {
+			return "", fmt.Errorf("unknown event type %q", t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	b, err := json.Marshal(out)
+	return string(b), err
+}
+
+// newSecret returns a random signing secret.
+func newSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
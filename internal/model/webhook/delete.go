@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+)
+
+// attachDeleteRoutes registers the delete (DELETE) endpoint.
+func attachDeleteRoutes(r chi.Router, db *sql.DB) {
+	r.Delete("/{webhookID}", func(w http.ResponseWriter, r *http.Request) { deleteEndpoint(db, w, r) })
+}
+
+// deleteEndpoint handles DELETE /api/webhooks/{webhookID}
+//
+// @Summary      Delete a webhook endpoint
+// @Description  Removes the endpoint together with its delivery log. Use PATCH with active=false to pause it instead.
+// @Tags         webhooks
+// @Param        webhookID  path  string  true  "Webhook endpoint ID"
+// @Success      204
+// @Failure      403  {object}  businessuser.MissingPermissionResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/webhooks/{webhookID} [delete]
+func deleteEndpoint(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	ep, ok := loadEndpointForAdmin(ctx, db, w, chi.URLParam(r, "webhookID"), u)
+	if !ok {
+		return
+	}
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM webhook_endpoint WHERE id = $1`, ep.ID); err != nil {
+		slog.Error("delete webhook endpoint failed", slog.String("webhook_id", ep.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
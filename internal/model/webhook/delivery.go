@@ -0,0 +1,256 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/pagination"
+)
+
+// attachDeliveryRoutes registers the delivery log and redelivery endpoints.
+func attachDeliveryRoutes(r chi.Router, db *sql.DB) {
+	r.Get("/{webhookID}/deliveries", func(w http.ResponseWriter, r *http.Request) { listDeliveries(db, w, r) })
+	r.Get("/{webhookID}/deliveries/{deliveryID}", func(w http.ResponseWriter, r *http.Request) { getDelivery(db, w, r) })
+	r.Post("/{webhookID}/deliveries/{deliveryID}/redeliver", func(w http.ResponseWriter, r *http.Request) { redeliver(db, w, r) })
+}
+
+// listDeliveries handles GET /api/webhooks/{webhookID}/deliveries
+//
+// @Summary      List webhook deliveries
+// @Description  Returns the most recent deliveries to the endpoint, newest first.
+// @Tags         webhooks
+// @Produce      json
+// @Param        webhookID  path      string  true   "Webhook endpoint ID"
+// @Param        status     query     string  false  "pending, succeeded or failed"
+// @Param        limit      query     int     false  "Maximum number of deliveries (1-200, default 50)"
+// @Success      200        {array}   Delivery
+// @Failure      400        {object}  ErrorResponse
+// @Failure      403        {object}  businessuser.MissingPermissionResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /api/webhooks/{webhookID}/deliveries [get]
+func listDeliveries(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	limit, err := pagination.ParseLimit(q.Get("limit"))
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	status := q.Get("status")
+	switch status {
+	case "", deliveryPending, deliverySucceeded, deliveryFailed:
+	default:
+		httpx.WriteErr(w, http.StatusBadRequest, "status must be one of pending, succeeded, failed")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	ep, ok := loadEndpointForAdmin(ctx, db, w, chi.URLParam(r, "webhookID"), u)
+	if !ok {
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_delivery d
+		WHERE d.endpoint_id = $1 AND ($2 = '' OR d.status = $2)
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $3`,
+		ep.ID, status, limit,
+	)
+	if err != nil {
+		slog.Error("query webhook deliveries failed", slog.String("webhook_id", ep.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+
+	deliveries := make([]Delivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			slog.Error("scan webhook delivery row failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error("rows error after iteration", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(deliveries)
+}
+
+// getDelivery handles GET /api/webhooks/{webhookID}/deliveries/{deliveryID}
+//
+// @Summary      Get a webhook delivery
+// @Description  Returns a delivery with the exact payload sent and the request/response log of every attempt.
+// @Tags         webhooks
+// @Produce      json
+// @Param        webhookID   path      string  true  "Webhook endpoint ID"
+// @Param        deliveryID  path      string  true  "Delivery ID"
+// @Success      200         {object}  DeliveryDetail
+// @Failure      403         {object}  businessuser.MissingPermissionResponse
+// @Failure      404         {object}  ErrorResponse
+// @Router       /api/webhooks/{webhookID}/deliveries/{deliveryID} [get]
+func getDelivery(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	deliveryID := chi.URLParam(r, "deliveryID")
+	logger := slog.Default().With(
+		slog.String("component", "webhooks"),
+		slog.String("op", "getDelivery"),
+		slog.String("delivery_id", deliveryID),
+	)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	ep, ok := loadEndpointForAdmin(ctx, db, w, chi.URLParam(r, "webhookID"), u)
+	if !ok {
+		return
+	}
+	d, ok := loadDelivery(ctx, db, w, ep.ID, deliveryID)
+	if !ok {
+		return
+	}
+
+	var payload []byte
+	if err := db.QueryRowContext(ctx, `SELECT payload FROM webhook_event WHERE id = $1`, d.EventID).Scan(&payload); err != nil {
+		logger.Error("query webhook event failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	detail := DeliveryDetail{Delivery: d, Payload: payload, Attempts: []Attempt{}}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT attempt, response_status, response_body, error, duration_ms, created_at
+		FROM webhook_delivery_attempt
+		WHERE delivery_id = $1
+		ORDER BY attempt`,
+		d.ID,
+	)
+	if err != nil {
+		logger.Error("query webhook attempts failed", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a Attempt
+		if err := rows.Scan(&a.Attempt, &a.ResponseStatus, &a.ResponseBody, &a.Error, &a.DurationMS, &a.CreatedAt); err != nil {
+			logger.Error("scan webhook attempt failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		detail.Attempts = append(detail.Attempts, a)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("rows error after iteration", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(detail)
+}
+
+// redeliver handles POST /api/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver
+//
+// @Summary      Redeliver a webhook event
+// @Description  Schedules a new delivery of the same event to the endpoint, sent as soon as possible. The original delivery and its log are kept.
+// @Tags         webhooks
+// @Produce      json
+// @Param        webhookID   path      string  true  "Webhook endpoint ID"
+// @Param        deliveryID  path      string  true  "Delivery ID"
+// @Success      202         {object}  Delivery
+// @Failure      403         {object}  businessuser.MissingPermissionResponse
+// @Failure      404         {object}  ErrorResponse
+// @Failure      409         {object}  ErrorResponse  "Endpoint is inactive"
+// @Router       /api/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver [post]
+func redeliver(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	ep, ok := loadEndpointForAdmin(ctx, db, w, chi.URLParam(r, "webhookID"), u)
+	if !ok {
+		return
+	}
+	if !ep.Active {
+		httpx.WriteErr(w, http.StatusConflict, "webhook endpoint is inactive")
+		return
+	}
+	orig, ok := loadDelivery(ctx, db, w, ep.ID, chi.URLParam(r, "deliveryID"))
+	if !ok {
+		return
+	}
+
+	d, err := scanDelivery(db.QueryRowContext(ctx, `
+		WITH d AS (
+			INSERT INTO webhook_delivery (event_id, endpoint_id)
+			VALUES ($1, $2)
+			RETURNING *
+		)
+		SELECT `+deliveryColumns+` FROM d`,
+		orig.EventID, ep.ID,
+	))
+	if err != nil {
+		slog.Error("insert redelivery failed", slog.String("delivery_id", orig.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusAccepted, d)
+}
+
+// loadDelivery fetches a delivery of the given endpoint. Returns false after writing 404 or 500.
+func loadDelivery(ctx context.Context, db *sql.DB, w http.ResponseWriter, endpointID, deliveryID string) (Delivery, bool) {
+	if _, err := uuid.Parse(deliveryID); err != nil {
+		httpx.WriteErr(w, http.StatusNotFound, "delivery not found")
+		return Delivery{}, false
+	}
+	d, err := scanDelivery(db.QueryRowContext(ctx,
+		`SELECT `+deliveryColumns+` FROM webhook_delivery d WHERE d.id = $1 AND d.endpoint_id = $2`,
+		deliveryID, endpointID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusNotFound, "delivery not found")
+		return Delivery{}, false
+	} else if err != nil {
+		slog.Error("query webhook delivery failed", slog.String("delivery_id", deliveryID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Delivery{}, false
+	}
+	return d, true
+}
@@ -0,0 +1,302 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+const (
+	// HeaderSignature carries "v1=<hex hmac-sha256>" of "<timestamp>.<body>" keyed with the endpoint secret.
+	HeaderSignature = "Payway-Signature"
+	// HeaderTimestamp is the Unix time the request was signed at; receivers should reject old timestamps.
+	HeaderTimestamp  = "Payway-Timestamp"
+	HeaderEventID    = "Payway-Event-Id"
+	HeaderEventType  = "Payway-Event-Type"
+	HeaderDeliveryID = "Payway-Delivery-Id"
+
+	// maxAttempts is how often a delivery is tried before it is marked failed.
+	maxAttempts = 10
+	// batchSize bounds how many deliveries a dispatcher claims at once.
+	batchSize = 20
+	// lease is how long a claimed delivery is hidden from other dispatchers while it is being sent.
+	lease = time.Minute
+	// requestTimeout bounds a single delivery request.
+	requestTimeout = 10 * time.Second
+	// drainTimeout is how long deliveries in flight may finish after shutdown begins.
+	drainTimeout = 5 * time.Second
+	// maxLoggedBody is how much of a receiver's response is kept in the attempt log.
+	maxLoggedBody = 4 << 10
+)
+
+// RunDispatcher sends due deliveries every interval until ctx is cancelled.
+// Several replicas may run it concurrently; claimed deliveries are leased to one of them.
+// On cancellation, deliveries in flight get drainTimeout to finish and the rest of the claimed
+// batch is released so other replicas pick it up without waiting for the lease to expire.
+// Unless allowInsecure is set, deliveries to internal addresses fail without reaching them.
+func RunDispatcher(ctx context.Context, db *sql.DB, interval time.Duration, allowInsecure bool) {
+	client := newDeliveryClient(allowInsecure)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			for ctx.Err() == nil {
+				n, err := dispatchDue(ctx, db, client)
+				if err != nil {
+					slog.Warn("dispatch webhooks failed", slog.Any("err", err))
+				}
+				if err != nil || n < batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// pendingDelivery is a claimed delivery with everything needed to send it.
+type pendingDelivery struct {
+	id        string
+	attempts  int
+	url       string
+	secret    string
+	active    bool
+	eventID   string
+	eventType string
+	payload   []byte
+}
+
+// dispatchDue claims a batch of due deliveries and sends them. It returns the batch size.
+func dispatchDue(ctx context.Context, db *sql.DB, client *http.Client) (int, error) {
+	rows, err := db.QueryContext(ctx, `
+		UPDATE webhook_delivery d
+		SET next_attempt_at = now() + make_interval(secs => $1)
+		FROM webhook_endpoint e, webhook_event ev
+		WHERE d.id IN (
+			SELECT id FROM webhook_delivery
+			WHERE status = $2 AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		) AND e.id = d.endpoint_id AND ev.id = d.event_id
+		RETURNING d.id, d.attempts, e.url, e.secret, e.active, ev.id, ev.type, ev.payload`,
+		lease.Seconds(), deliveryPending, batchSize,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var batch []pendingDelivery
+	for rows.Next() {
+		var d pendingDelivery
+		if err := rows.Scan(&d.id, &d.attempts, &d.url, &d.secret, &d.active, &d.eventID, &d.eventType, &d.payload); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch = append(batch, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	work, stop := drainContext(ctx, drainTimeout)
+	defer stop()
+	var unfinished []string
+	for _, d := range batch {
+		if ctx.Err() != nil {
+			unfinished = append(unfinished, d.id)
+			continue
+		}
+		if err := deliver(work, db, client, d); err != nil {
+			if work.Err() != nil {
+				unfinished = append(unfinished, d.id)
+				continue
+			}
+			slog.Error("record webhook attempt failed", slog.String("delivery_id", d.id), slog.Any("err", err))
+		}
+	}
+	if len(unfinished) > 0 {
+		if err := releaseLeases(ctx, db, unfinished); err != nil {
+			slog.Error("release webhook leases failed", slog.Int("deliveries", len(unfinished)), slog.Any("err", err))
+		}
+	}
+	return len(batch), nil
+}
+
+// drainContext returns a context that outlives ctx by grace, so work in flight when ctx is
+// cancelled can finish. stop must be called to release its resources.
+func drainContext(ctx context.Context, grace time.Duration) (work context.Context, stop func()) {
+	work, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	unregister := context.AfterFunc(ctx, func() { time.AfterFunc(grace, cancel) })
+	return work, func() {
+		unregister()
+		cancel()
+	}
+}
+
+// releaseLeases makes claimed deliveries that were not attempted due again immediately.
+func releaseLeases(ctx context.Context, db *sql.DB, ids []string) error {
+	// ctx may already be cancelled when shutting down; the release itself must still run.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), drainTimeout)
+	defer cancel()
+	_, err := db.ExecContext(ctx, `
+		UPDATE webhook_delivery
+		SET next_attempt_at = now()
+		WHERE id = ANY($1::text[]::uuid[]) AND status = $2`,
+		ids, deliveryPending,
+	)
+	return err
+}
+
+// deliver sends one delivery and records the outcome. Failures are retried with exponential
+// backoff until maxAttempts is reached. Deliveries to deactivated endpoints fail without a request.
+func deliver(ctx context.Context, db *sql.DB, client *http.Client, d pendingDelivery) error {
+	if !d.active {
+		_, err := db.ExecContext(ctx, `
+			UPDATE webhook_delivery
+			SET status = $2, last_error = 'endpoint is inactive', completed_at = now()
+			WHERE id = $1`,
+			d.id, deliveryFailed,
+		)
+		return err
+	}
+
+	attempt := d.attempts + 1
+	start := time.Now()
+	status, body, sendErr := send(ctx, client, d, start)
+	elapsed := time.Since(start)
+	if ctx.Err() != nil {
+		// Cut short by shutdown; the caller releases the lease instead of recording an attempt.
+		return ctx.Err()
+	}
+
+	var errMsg *string
+	if sendErr != nil {
+		msg := sendErr.Error()
+		errMsg = &msg
+	}
+	var respStatus *int
+	if status != 0 {
+		respStatus = &status
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO webhook_delivery_attempt (delivery_id, attempt, response_status, response_body, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		d.id, attempt, respStatus, nullIfEmpty(body), errMsg, elapsed.Milliseconds(),
+	); err != nil {
+		return err
+	}
+
+	switch {
+	case sendErr == nil:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE webhook_delivery
+			SET status = $2, attempts = $3, last_attempt_at = now(), response_status = $4, last_error = NULL, completed_at = now()
+			WHERE id = $1`,
+			d.id, deliverySucceeded, attempt, respStatus,
+		)
+	case attempt >= maxAttempts:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE webhook_delivery
+			SET status = $2, attempts = $3, last_attempt_at = now(), response_status = $4, last_error = $5, completed_at = now()
+			WHERE id = $1`,
+			d.id, deliveryFailed, attempt, respStatus, errMsg,
+		)
+	default:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE webhook_delivery
+			SET attempts = $2, last_attempt_at = now(), response_status = $3, last_error = $4,
+				next_attempt_at = now() + make_interval(secs => $5)
+			WHERE id = $1`,
+			d.id, attempt, respStatus, errMsg, retryDelay(attempt).Seconds(),
+		)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// send posts the signed payload. Any non-2xx response counts as a failure.
+func send(ctx context.Context, client *http.Client, d pendingDelivery, now time.Time) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(d.payload))
+	if err != nil {
+		return 0, "", err
+	}
+	ts := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Payway-Webhooks/1.0")
+	req.Header.Set(HeaderTimestamp, ts)
+	req.Header.Set(HeaderSignature, "v1="+Sign(d.secret, ts, d.payload))
+	req.Header.Set(HeaderEventID, d.eventID)
+	req.Header.Set(HeaderEventType, d.eventType)
+	req.Header.Set(HeaderDeliveryID, d.id)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBody))
+	// The body is stored as text, which rejects NUL bytes and invalid UTF-8.
+	body := strings.ToValidUTF8(strings.ReplaceAll(string(raw), "\x00", ""), "\uFFFD")
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, body, fmt.Errorf("receiver responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with secret,
+// which receivers recompute to verify a delivery.
+func Sign(secret, timestamp string, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(timestamp))
+	m.Write([]byte("."))
+	m.Write(body)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// retryDelay is the wait after the given failed attempt: about 1m, 2m, 4m, ... capped at 6h, with jitter.
+func retryDelay(attempt int) time.Duration {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = time.Minute
+	b.Multiplier = 2
+	b.MaxInterval = 6 * time.Hour
+	b.RandomizationFactor = 0.2
+	b.MaxElapsedTime = 0
+	b.Reset()
+
+	d := b.NextBackOff()
+	for i := 1; i < attempt; i++ {
+		d = b.NextBackOff()
+	}
+	return d
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDrainContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	work, stop := drainContext(ctx, 50*time.Millisecond)
+	defer stop()
+
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	if work.Err() != nil {
+		t.Fatal("work was cancelled before the drain timeout")
+	}
+	select {
+	case <-work.Done():
+	case <-time.After(time.Second):
+		t.Fatal("work outlived the drain timeout")
+	}
+}
+
+func TestDrainContextStop(t *testing.T) {
+	work, stop := drainContext(context.Background(), time.Hour)
+	stop()
+	if work.Err() == nil {
+		t.Fatal("stop did not cancel work")
+	}
+}
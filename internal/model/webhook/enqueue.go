@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Enqueue records an event and schedules a delivery to every active endpoint of the business
+// subscribed to typ. It runs inside the caller's transaction, so events are only sent for
+// changes that commit. Nothing is stored when no endpoint is subscribed.
+// data is marshalled to JSON and becomes the event's data field.
+func Enqueue(ctx context.Context, tx *sql.Tx, businessID string, typ EventType, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	ev := Event{
+		ID:         uuid.NewString(),
+		Type:       typ,
+		BusinessID: businessID,
+		CreatedAt:  time.Now().UTC(),
+		Data:       raw,
+	}
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		WITH subscribed AS (
+			SELECT id FROM webhook_endpoint
+			WHERE business_id = $2 AND active AND event_types @> jsonb_build_array($3::text)
+		), ev AS (
+			INSERT INTO webhook_event (id, business_id, type, payload, created_at)
+			SELECT $1, $2, $3, $4::jsonb, $5
+			WHERE EXISTS (SELECT 1 FROM subscribed)
+			RETURNING id
+		)
+		INSERT INTO webhook_delivery (event_id, endpoint_id)
+		SELECT ev.id, subscribed.id FROM ev, subscribed`,
+		ev.ID, businessID, string(typ), string(payload), ev.CreatedAt,
+	)
+	return err
+}
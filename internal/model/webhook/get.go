@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+	"backend/internal/model/businessuser"
+)
+
+// attachGetRoutes registers the list and single-endpoint (GET) endpoints.
+func attachGetRoutes(r chi.Router, db *sql.DB) {
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) { listEndpoints(db, w, r) })
+	r.Get("/{webhookID}", func(w http.ResponseWriter, r *http.Request) { getEndpoint(db, w, r) })
+}
+
+// listEndpoints handles GET /api/webhooks?business_id=...
+//
+// @Summary      List webhook endpoints
+// @Description  Returns the webhook endpoints of a business, newest first. Secrets are not included.
+// @Tags         webhooks
+// @Produce      json
+// @Param        business_id  query     string  true  "Business ID"
+// @Success      200          {array}   Endpoint
+// @Failure      400          {object}  ErrorResponse
+// @Failure      403          {object}  businessuser.MissingPermissionResponse
+// @Router       /api/webhooks [get]
+func listEndpoints(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	bizID := strings.TrimSpace(r.URL.Query().Get("business_id"))
+	if bizID == "" {
+		httpx.WriteErr(w, http.StatusBadRequest, "business_id is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if !businessuser.AssertPermission(ctx, db, w, bizID, u, businessuser.PermBusinessManage) {
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+endpointColumns+`
+		FROM webhook_endpoint
+		WHERE business_id = $1::uuid
+		ORDER BY created_at DESC`,
+		bizID,
+	)
+	if err != nil {
+		slog.Error("query webhook endpoints failed", slog.String("business_id", bizID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+	defer rows.Close()
+
+	endpoints := make([]Endpoint, 0)
+	for rows.Next() {
+		ep, err := scanEndpoint(rows)
+		if err != nil {
+			slog.Error("scan webhook endpoint row failed", slog.Any("err", err))
+			httpx.WriteInternalServerError(w)
+			return
+		}
+		endpoints = append(endpoints, ep)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error("rows error after iteration", slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(endpoints)
+}
+
+// getEndpoint handles GET /api/webhooks/{webhookID}
+//
+// @Summary      Get a webhook endpoint
+// @Tags         webhooks
+// @Produce      json
+// @Param        webhookID  path      string  true  "Webhook endpoint ID"
+// @Success      200        {object}  Endpoint
+// @Failure      403        {object}  businessuser.MissingPermissionResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /api/webhooks/{webhookID} [get]
+func getEndpoint(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	ep, ok := loadEndpointForAdmin(ctx, db, w, chi.URLParam(r, "webhookID"), u)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ep)
+}
+
+// loadEndpointForAdmin fetches an endpoint and checks the caller may manage its business.
+// Returns false after writing 404, 403 or 500.
+func loadEndpointForAdmin(ctx context.Context, db *sql.DB, w http.ResponseWriter, endpointID string, u *auth.User) (Endpoint, bool) {
+	if _, err := uuid.Parse(endpointID); err != nil {
+		httpx.WriteErr(w, http.StatusNotFound, "webhook endpoint not found")
+		return Endpoint{}, false
+	}
+	ep, err := scanEndpoint(db.QueryRowContext(ctx,
+		`SELECT `+endpointColumns+` FROM webhook_endpoint WHERE id = $1`, endpointID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		httpx.WriteErr(w, http.StatusNotFound, "webhook endpoint not found")
+		return Endpoint{}, false
+	} else if err != nil {
+		slog.Error("query webhook endpoint failed", slog.String("webhook_id", endpointID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return Endpoint{}, false
+	}
+	if !businessuser.AssertPermission(ctx, db, w, ep.BusinessID, u, businessuser.PermBusinessManage) {
+		return Endpoint{}, false
+	}
+	return ep, true
+}
@@ -0,0 +1,21 @@
+package webhook
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Routes aggregates all webhook submodule routes (endpoints, secrets, delivery log).
+// allowInsecure accepts http URLs and internal addresses; it is meant for local development only.
+func Routes(db *sql.DB, allowInsecure bool) http.Handler {
+	r := chi.NewRouter()
+	attachCreateRoutes(r, db, allowInsecure)
+	attachGetRoutes(r, db)
+	attachUpdateRoutes(r, db, allowInsecure)
+	attachDeleteRoutes(r, db)
+	attachSecretRoutes(r, db)
+	attachDeliveryRoutes(r, db)
+	return r
+}
@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+)
+
+// attachSecretRoutes registers the secret rotation endpoint.
+func attachSecretRoutes(r chi.Router, db *sql.DB) {
+	r.Post("/{webhookID}/rotate-secret", func(w http.ResponseWriter, r *http.Request) { rotateSecret(db, w, r) })
+}
+
+// rotateSecret handles POST /api/webhooks/{webhookID}/rotate-secret
+//
+// @Summary      Rotate a webhook signing secret
+// @Description  Issues a new signing secret. Deliveries sent from now on, including retries, are signed with it.
+// @Tags         webhooks
+// @Produce      json
+// @Param        webhookID  path      string  true  "Webhook endpoint ID"
+// @Success      200        {object}  EndpointWithSecret
+// @Failure      403        {object}  businessuser.MissingPermissionResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /api/webhooks/{webhookID}/rotate-secret [post]
+func rotateSecret(db *sql.DB, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	cur, ok := loadEndpointForAdmin(ctx, db, w, chi.URLParam(r, "webhookID"), u)
+	if !ok {
+		return
+	}
+
+	secret := newSecret()
+	ep, err := scanEndpoint(db.QueryRowContext(ctx, `
+		UPDATE webhook_endpoint SET secret = $2, updated_at = now()
+		WHERE id = $1
+		RETURNING `+endpointColumns,
+		cur.ID, secret,
+	))
+	if err != nil {
+		slog.Error("rotate webhook secret failed", slog.String("webhook_id", cur.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, EndpointWithSecret{Endpoint: ep, Secret: secret})
+}
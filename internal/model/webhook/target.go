@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// errForbiddenTarget is returned when a webhook URL resolves to an address inside our network.
+var errForbiddenTarget = errors.New("url must not point to a loopback, link-local or private address")
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which netip does not count as private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// forbiddenAddr reports whether webhooks must not be sent to addr: loopback, link-local (which
+// includes cloud metadata services), private and other non-public addresses.
+func forbiddenAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || sharedAddressSpace.Contains(addr)
+}
+
+// validateURL requires an absolute https URL whose host resolves only to public addresses.
+// With allowInsecure, as in local development, http and any address are accepted too.
+func validateURL(ctx context.Context, raw string, allowInsecure bool) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && (u.Scheme != "http" || !allowInsecure)) {
+		if allowInsecure {
+			return "", errors.New("url must be an absolute http or https URL")
+		}
+		return "", errors.New("url must be an absolute https URL")
+	}
+	if u.User != nil {
+		return "", errors.New("url must not contain credentials")
+	}
+	if allowInsecure {
+		return raw, nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return "", errors.New("url host cannot be resolved")
+	}
+	for _, addr := range addrs {
+		if forbiddenAddr(addr) {
+			return "", errForbiddenTarget
+		}
+	}
+	return raw, nil
+}
+
+// newDeliveryClient returns the HTTP client deliveries are sent with. Unless allowInsecure is set,
+// every connection is checked after DNS resolution, so a host that resolved to a public address
+// at registration cannot be rebound to an internal one. Proxies and redirects are not followed.
+func newDeliveryClient(allowInsecure bool) *http.Client {
+	dialer := &net.Dialer{Timeout: requestTimeout}
+	if !allowInsecure {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || forbiddenAddr(addrPort.Addr()) {
+				return errForbiddenTarget
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Timeout: requestTimeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   requestTimeout,
+			ResponseHeaderTimeout: requestTimeout,
+			IdleConnTimeout:       90 * time.Second,
+			MaxIdleConnsPerHost:   2,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
@@ -0,0 +1,140 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType names something that happened to a business's resources.
+type EventType string
+
+const (
+	EventOrderCreated   EventType = "order.created"
+	EventOrderPaid      EventType = "order.paid"
+	EventOrderFulfilled EventType = "order.fulfilled"
+	EventOrderCancelled EventType = "order.cancelled"
+	EventOrderExpired   EventType = "order.expired"
+	EventOrderRefunded  EventType = "order.refunded"
+
+	EventOrderPartiallyRefunded EventType = "order.partially_refunded"
+)
+
+// eventTypes lists every type an endpoint may subscribe to.
+var eventTypes = []EventType{
+	EventOrderCreated,
+	EventOrderPaid,
+	EventOrderFulfilled,
+	EventOrderCancelled,
+	EventOrderExpired,
+	EventOrderRefunded,
+	EventOrderPartiallyRefunded,
+}
+
+func (t EventType) valid() bool {
+	for _, known := range eventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Endpoint is a URL a business registered to receive events.
+type Endpoint struct {
+	ID          string      `json:"id"`
+	BusinessID  string      `json:"business_id"`
+	URL         string      `json:"url"`
+	Description *string     `json:"description,omitempty"`
+	EventTypes  []EventType `json:"event_types"`
+	Active      bool        `json:"active"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// EndpointWithSecret is returned when a signing secret is issued (create and rotate).
+// The secret is not shown again afterwards.
+type EndpointWithSecret struct {
+	Endpoint
+	Secret string `json:"secret"`
+}
+
+// Event is the JSON body of every delivery.
+type Event struct {
+	ID         string          `json:"id"`
+	Type       EventType       `json:"type"`
+	BusinessID string          `json:"business_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Data       json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+const (
+	deliveryPending   = "pending"
+	deliverySucceeded = "succeeded"
+	deliveryFailed    = "failed"
+)
+
+// Delivery tracks sending one event to one endpoint, including retries.
+type Delivery struct {
+	ID             string     `json:"id"`
+	EventID        string     `json:"event_id"`
+	EventType      EventType  `json:"event_type"`
+	EndpointID     string     `json:"endpoint_id"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"` // only set while pending
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	ResponseStatus *int       `json:"response_status,omitempty"`
+	LastError      *string    `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+// DeliveryDetail is a delivery together with the event it carries and every attempt made.
+type DeliveryDetail struct {
+	Delivery
+	Payload  json.RawMessage `json:"payload" swaggertype:"object"`
+	Attempts []Attempt       `json:"attempt_log"`
+}
+
+// Attempt is one HTTP request made for a delivery.
+type Attempt struct {
+	Attempt        int       `json:"attempt"`
+	ResponseStatus *int      `json:"response_status,omitempty"`
+	ResponseBody   *string   `json:"response_body,omitempty"`
+	Error          *string   `json:"error,omitempty"`
+	DurationMS     int       `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// endpointColumns is the column list scanned by scanEndpoint.
+const endpointColumns = `id, business_id, url, description, event_types, active, created_at, updated_at`
+
+// deliveryColumns is the column list scanned by scanDelivery, for webhook_delivery aliased as d.
+const deliveryColumns = `d.id, d.event_id, (SELECT ev.type FROM webhook_event ev WHERE ev.id = d.event_id), d.endpoint_id, d.status, d.attempts,
+	d.next_attempt_at, d.last_attempt_at, d.response_status, d.last_error, d.created_at, d.completed_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEndpoint(row rowScanner) (Endpoint, error) {
+	var e Endpoint
+	var types []byte
+	if err := row.Scan(&e.ID, &e.BusinessID, &e.URL, &e.Description, &types, &e.Active, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return Endpoint{}, err
+	}
+	if err := json.Unmarshal(types, &e.EventTypes); err != nil {
+		return Endpoint{}, err
+	}
+	return e, nil
+}
+
+func scanDelivery(row rowScanner) (Delivery, error) {
+	var d Delivery
+	err := row.Scan(&d.ID, &d.EventID, &d.EventType, &d.EndpointID, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastAttemptAt, &d.ResponseStatus, &d.LastError, &d.CreatedAt, &d.CompletedAt)
+	if d.Status != deliveryPending {
+		d.NextAttemptAt = nil
+	}
+	return d, err
+}
@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"backend/internal/auth"
+	"backend/internal/httpx"
+)
+
+// EndpointUpdatePayload is the body accepted by PATCH /api/webhooks/{webhookID}. Omitted fields are left unchanged.
+type EndpointUpdatePayload struct {
+	URL         *string      `json:"url"`
+	Description *string      `json:"description"`
+	EventTypes  *[]EventType `json:"event_types"`
+	Active      *bool        `json:"active"`
+}
+
+// attachUpdateRoutes registers the update (PATCH) endpoint.
+func attachUpdateRoutes(r chi.Router, db *sql.DB, allowInsecure bool) {
+	r.Patch("/{webhookID}", func(w http.ResponseWriter, r *http.Request) { updateEndpoint(db, allowInsecure, w, r) })
+}
+
+// updateEndpoint handles PATCH /api/webhooks/{webhookID}
+//
+// @Summary      Update a webhook endpoint
+// @Description  Changes the URL, description or subscribed event types, or pauses the endpoint with active=false. Deliveries that come due while the endpoint is inactive are marked failed and can be redelivered later.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        webhookID  path      string                 true  "Webhook endpoint ID"
+// @Param        payload    body      EndpointUpdatePayload  true  "Fields to update"
+// @Success      200        {object}  Endpoint
+// @Failure      400        {object}  ErrorResponse
+// @Failure      403        {object}  businessuser.MissingPermissionResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /api/webhooks/{webhookID} [patch]
+func updateEndpoint(db *sql.DB, allowInsecure bool, w http.ResponseWriter, r *http.Request) {
+
+	u, ok := auth.FirebaseUser(w, r)
+	if !ok {
+		return
+	}
+
+	defer r.Body.Close()
+	var p EndpointUpdatePayload
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		httpx.WriteBadRequest(w)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	cur, ok := loadEndpointForAdmin(ctx, db, w, chi.URLParam(r, "webhookID"), u)
+	if !ok {
+		return
+	}
+
+	endpointURL := cur.URL
+	if p.URL != nil {
+		var err error
+		if endpointURL, err = validateURL(ctx, *p.URL, allowInsecure); err != nil {
+			httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	description := cur.Description
+	if p.Description != nil {
+		d := strings.TrimSpace(*p.Description)
+		description = &d
+		if d == "" {
+			description = nil
+		}
+	}
+	eventTypes := cur.EventTypes
+	if p.EventTypes != nil {
+		eventTypes = *p.EventTypes
+	}
+	types, err := validateEventTypes(eventTypes)
+	if err != nil {
+		httpx.WriteErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	active := cur.Active
+	if p.Active != nil {
+		active = *p.Active
+	}
+
+	ep, err := scanEndpoint(db.QueryRowContext(ctx, `
+		UPDATE webhook_endpoint
+		SET url = $2, description = $3, event_types = $4::jsonb, active = $5, updated_at = now()
+		WHERE id = $1
+		RETURNING `+endpointColumns,
+		cur.ID, endpointURL, description, types, active,
+	))
+	if err != nil {
+		slog.Error("update webhook endpoint failed", slog.String("webhook_id", cur.ID), slog.Any("err", err))
+		httpx.WriteInternalServerError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ep)
+}
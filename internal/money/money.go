@@ -0,0 +1,113 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidAmount  = errors.New("amount must be a decimal number")
+	ErrAmountOverflow = errors.New("amount is too large")
+)
+
+// TooManyDecimalsError is returned when an amount has more fractional digits than its currency allows.
+type TooManyDecimalsError struct {
+	Currency string
+	Exponent int
+}
+
+func (e *TooManyDecimalsError) Error() string {
+	if e.Exponent == 0 {
+		return fmt.Sprintf("%s amounts cannot have decimals", e.Currency)
+	}
+	return fmt.Sprintf("%s amounts allow at most %d decimal places", e.Currency, e.Exponent)
+}
+
+// Amount is a monetary value stored as an integer number of minor units (e.g. cents) of Currency.
+type Amount struct {
+	Minor    int64
+	Currency string
+}
+
+// FromMinor builds an Amount from minor units.
+func FromMinor(minor int64, currency string) Amount {
+	return Amount{Minor: minor, Currency: currency}
+}
+
+// Parse converts a decimal string such as "12.34" into minor units of currency.
+// Trailing zeros are ignored, so "1.50" is valid for a 1-decimal currency, but
+// any remaining digits beyond the currency's exponent are rejected.
+func Parse(s string, currency string) (Amount, error) {
+	exp := Exponent(currency)
+
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	intPart, frac, hasDot := strings.Cut(s, ".")
+	if intPart == "" || !isDigits(intPart) || (hasDot && (frac == "" || !isDigits(frac))) {
+		return Amount{}, ErrInvalidAmount
+	}
+
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > exp {
+		return Amount{}, &TooManyDecimalsError{Currency: currency, Exponent: exp}
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	minor, err := strconv.ParseInt(intPart+frac, 10, 64)
+	if err != nil {
+		return Amount{}, ErrAmountOverflow
+	}
+	if neg {
+		minor = -minor
+	}
+	return Amount{Minor: minor, Currency: currency}, nil
+}
+
+// String formats the amount as a plain decimal with exactly the currency's number of decimals.
+func (a Amount) String() string {
+	exp := Exponent(a.Currency)
+	minor := a.Minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatUint(absUint(minor), 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	cut := len(digits) - exp
+	return sign + digits[:cut] + "." + digits[cut:]
+}
+
+// IsPositive reports whether the amount is greater than zero.
+func (a Amount) IsPositive() bool {
+	return a.Minor > 0
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func absUint(v int64) uint64 {
+	if v == math.MinInt64 {
+		return uint64(math.MaxInt64) + 1
+	}
+	if v < 0 {
+		return uint64(-v)
+	}
+	return uint64(v)
+}
@@ -0,0 +1,130 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     int64
+		err      error // nil for success; checked with errors.Is, or errors.As for *TooManyDecimalsError
+	}{
+		{"12.34", "BZD", 1234, nil},
+		{"12", "BZD", 1200, nil},
+		{"0", "BZD", 0, nil},
+		{"0.01", "BZD", 1, nil},
+		{"007.5", "BZD", 750, nil},
+		{"  3.10 ", "BZD", 310, nil},
+		{"1.50", "BZD", 150, nil},
+		{"1.500000", "BZD", 150, nil},
+		{"10.00", "JPY", 10, nil},
+		{"10", "JPY", 10, nil},
+		{"1.234", "BHD", 1234, nil},
+		{"1.2340", "BHD", 1234, nil},
+		{"1.0001", "CLF", 10001, nil},
+		{"-5", "BZD", -500, nil},
+		{"-0.05", "BZD", -5, nil},
+		{"-7", "JPY", -7, nil},
+		{"92233720368547758.07", "BZD", math.MaxInt64, nil},
+		{"9223372036854775807", "JPY", math.MaxInt64, nil},
+
+		{"1.234", "BZD", 0, &TooManyDecimalsError{}},
+		{"10.5", "JPY", 0, &TooManyDecimalsError{}},
+		{"1.23451", "BHD", 0, &TooManyDecimalsError{}},
+		{"1.00001", "CLF", 0, &TooManyDecimalsError{}},
+		{"-1.001", "BZD", 0, &TooManyDecimalsError{}},
+
+		{"92233720368547758.08", "BZD", 0, ErrAmountOverflow},
+		{"9223372036854775808", "JPY", 0, ErrAmountOverflow},
+		{"100000000000000000000", "BZD", 0, ErrAmountOverflow},
+
+		{"", "BZD", 0, ErrInvalidAmount},
+		{"-", "BZD", 0, ErrInvalidAmount},
+		{"1.", "BZD", 0, ErrInvalidAmount},
+		{".5", "BZD", 0, ErrInvalidAmount},
+		{"1e3", "BZD", 0, ErrInvalidAmount},
+		{"+1", "BZD", 0, ErrInvalidAmount},
+		{"--1", "BZD", 0, ErrInvalidAmount},
+		{"1,5", "BZD", 0, ErrInvalidAmount},
+		{"1.2.3", "BZD", 0, ErrInvalidAmount},
+		{"1 000", "BZD", 0, ErrInvalidAmount},
+		{"0x10", "BZD", 0, ErrInvalidAmount},
+		{"١٢", "BZD", 0, ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in, tt.currency)
+		switch want := tt.err.(type) {
+		case nil:
+			if err != nil {
+				t.Errorf("Parse(%q, %s): unexpected error %v", tt.in, tt.currency, err)
+			} else if got != (Amount{Minor: tt.want, Currency: tt.currency}) {
+				t.Errorf("Parse(%q, %s) = %+v, want %d minor units", tt.in, tt.currency, got, tt.want)
+			}
+		case *TooManyDecimalsError:
+			var te *TooManyDecimalsError
+			if !errors.As(err, &te) {
+				t.Errorf("Parse(%q, %s) error = %v, want a *TooManyDecimalsError", tt.in, tt.currency, err)
+			} else if te.Currency != tt.currency || te.Exponent != Exponent(tt.currency) {
+				t.Errorf("Parse(%q, %s) error = %+v", tt.in, tt.currency, te)
+			}
+		default:
+			if !errors.Is(err, want) {
+				t.Errorf("Parse(%q, %s) error = %v, want %v", tt.in, tt.currency, err, want)
+			}
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		minor    int64
+		currency string
+		want     string
+	}{
+		{0, "JPY", "0"},
+		{1234, "JPY", "1234"},
+		{-5, "JPY", "-5"},
+
+		{0, "BZD", "0.00"},
+		{1, "BZD", "0.01"},
+		{10, "BZD", "0.10"},
+		{150, "BZD", "1.50"},
+		{123456, "BZD", "1234.56"},
+		{-1, "BZD", "-0.01"},
+		{-150, "BZD", "-1.50"},
+		{math.MaxInt64, "BZD", "92233720368547758.07"},
+		{math.MinInt64, "BZD", "-92233720368547758.08"},
+
+		{0, "BHD", "0.000"},
+		{5, "BHD", "0.005"},
+		{1234, "BHD", "1.234"},
+		{-20, "BHD", "-0.020"},
+
+		{0, "CLF", "0.0000"},
+		{1, "CLF", "0.0001"},
+		{10001, "CLF", "1.0001"},
+		{-123456, "CLF", "-12.3456"},
+	}
+	for _, tt := range tests {
+		if got := FromMinor(tt.minor, tt.currency).String(); got != tt.want {
+			t.Errorf("FromMinor(%d, %s).String() = %q, want %q", tt.minor, tt.currency, got, tt.want)
+		}
+	}
+}
+
+// TestParseStringRoundTrip checks that formatting an amount and parsing it back is lossless.
+func TestParseStringRoundTrip(t *testing.T) {
+	for _, currency := range []string{"JPY", "BZD", "BHD", "CLF"} {
+		for _, minor := range []int64{0, 1, 9, 10, 99, 100, 12345, -1, -100, math.MaxInt64} {
+			a := FromMinor(minor, currency)
+			got, err := Parse(a.String(), currency)
+			if err != nil || got != a {
+				t.Errorf("Parse(%q, %s) = %+v, %v; want %+v", a.String(), currency, got, err, a)
+			}
+		}
+	}
+}
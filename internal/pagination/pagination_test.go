@@ -0,0 +1,101 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, c := range []Cursor{
+		{CreatedAt: time.Date(2026, 3, 14, 9, 30, 0, 123456000, time.UTC), ID: "6f1c2d3e-4a5b-4c6d-8e7f-901234567890"},
+		{CreatedAt: time.Date(2026, 3, 14, 9, 30, 0, 0, time.FixedZone("", 2*3600)), ID: "6f1c2d3e-4a5b-4c6d-8e7f-901234567890", Ascending: true},
+	} {
+		got, err := DecodeCursor(c.Encode())
+		if err != nil {
+			t.Fatalf("DecodeCursor(%+v): %v", c, err)
+		}
+		if !got.CreatedAt.Equal(c.CreatedAt) || got.ID != c.ID || got.Ascending != c.Ascending {
+			t.Fatalf("round trip of %+v = %+v", c, *got)
+		}
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	valid := Cursor{CreatedAt: time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC), ID: "6f1c2d3e-4a5b-4c6d-8e7f-901234567890"}.Encode()
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"not JSON", raw("created_at=2026-03-14")},
+		{"JSON array", raw(`["2026-03-14T09:30:00Z","6f1c2d3e-4a5b-4c6d-8e7f-901234567890"]`)},
+		{"missing time", raw(`{"id":"6f1c2d3e-4a5b-4c6d-8e7f-901234567890"}`)},
+		{"malformed time", raw(`{"t":"yesterday","id":"6f1c2d3e-4a5b-4c6d-8e7f-901234567890"}`)},
+		{"missing ID", raw(`{"t":"2026-03-14T09:30:00Z"}`)},
+		{"non-UUID ID", raw(`{"t":"2026-03-14T09:30:00Z","id":"42"}`)},
+		{"SQL in ID", raw(`{"t":"2026-03-14T09:30:00Z","id":"' OR 1=1 --"}`)},
+		{"truncated", valid[:len(valid)-4]},
+		{"tampered", valid[:10] + strings.Repeat("A", 4) + valid[14:]},
+	}
+	for _, tt := range tests {
+		if c, err := DecodeCursor(tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: DecodeCursor = %+v, %v; want %v", tt.name, c, err, ErrInvalidCursor)
+		}
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		err  bool
+	}{
+		{"", DefaultLimit, false},
+		{"1", 1, false},
+		{"200", 200, false},
+		{"0", 0, true},
+		{"-1", 0, true},
+		{"201", 0, true},
+		{"ten", 0, true},
+		{"1.5", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseLimit(%q) = %d, %v; want %d, error %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	desc := Cursor{CreatedAt: time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC), ID: "6f1c2d3e-4a5b-4c6d-8e7f-901234567890"}
+	asc := desc
+	asc.Ascending = true
+
+	limit, after, err := Parse(url.Values{}, false)
+	if err != nil || limit != DefaultLimit || after != nil {
+		t.Fatalf("first page = %d, %+v, %v", limit, after, err)
+	}
+	limit, after, err = Parse(url.Values{"limit": {"10"}, "cursor": {desc.Encode()}}, false)
+	if err != nil || limit != 10 || after == nil || after.ID != desc.ID {
+		t.Fatalf("next page = %d, %+v, %v", limit, after, err)
+	}
+	if _, _, err := Parse(url.Values{"cursor": {asc.Encode()}}, false); !errors.Is(err, ErrCursorMismatch) {
+		t.Fatalf("ascending cursor on a descending list: err = %v, want %v", err, ErrCursorMismatch)
+	}
+	if _, _, err := Parse(url.Values{"cursor": {desc.Encode()}}, true); !errors.Is(err, ErrCursorMismatch) {
+		t.Fatalf("descending cursor on an ascending list: err = %v, want %v", err, ErrCursorMismatch)
+	}
+	if _, _, err := Parse(url.Values{"limit": {"500"}}, false); !errors.Is(err, ErrInvalidLimit) {
+		t.Fatalf("limit 500: err = %v, want %v", err, ErrInvalidLimit)
+	}
+	if _, _, err := Parse(url.Values{"cursor": {"garbage"}}, false); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("garbage cursor: err = %v, want %v", err, ErrInvalidCursor)
+	}
+}
@@ -0,0 +1,109 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"backend/internal/money"
+)
+
+// Provider is implemented by every payment processor. Amounts are always in minor units.
+// Implementations must be safe for concurrent use.
+type Provider interface {
+	// Name is the stable identifier stored with payments and used in webhook URLs.
+	Name() string
+	// CreateIntent starts collecting req.Amount with the given payment method.
+	// A declined payment is reported as a *DeclineError.
+	CreateIntent(ctx context.Context, req IntentRequest) (Intent, error)
+	// Capture collects the funds of an intent in StatusRequiresCapture.
+	Capture(ctx context.Context, ref string) (Capture, error)
+	// Refund returns part or all of a captured payment to the customer.
+	Refund(ctx context.Context, req RefundRequest) (Refund, error)
+	// ParseWebhook verifies the signature of an inbound webhook and decodes it.
+	// An invalid signature is reported as ErrInvalidSignature.
+	ParseWebhook(r *http.Request) (Event, error)
+}
+
+// Status is the provider-side state of a payment intent.
+type Status string
+
+const (
+	StatusRequiresCapture Status = "requires_capture"
+	StatusProcessing      Status = "processing" // result arrives later through a webhook
+	StatusSucceeded       Status = "succeeded"
+	StatusFailed          Status = "failed"
+)
+
+// IntentRequest describes a payment to collect.
+// IdempotencyKey lets providers deduplicate retries; callers pass the local payment ID.
+type IntentRequest struct {
+	OrderID        string
+	Amount         money.Amount
+	PaymentMethod  string
+	Description    string
+	IdempotencyKey string
+}
+
+// Intent is the provider's view of a payment attempt.
+type Intent struct {
+	Ref    string
+	Status Status
+}
+
+// Capture is the result of capturing an intent. Fee is what the provider keeps.
+type Capture struct {
+	Status Status
+	Fee    money.Amount
+}
+
+// RefundRequest describes money to return for a captured payment.
+type RefundRequest struct {
+	PaymentRef     string
+	Amount         money.Amount
+	Reason         string
+	IdempotencyKey string
+}
+
+// Refund is the provider's view of a refund.
+type Refund struct {
+	Ref    string
+	Status Status
+}
+
+// EventType names an asynchronous notification from a provider.
+type EventType string
+
+const (
+	EventPaymentSucceeded EventType = "payment.succeeded"
+	EventPaymentFailed    EventType = "payment.failed"
+	EventRefundSucceeded  EventType = "refund.succeeded"
+	EventRefundFailed     EventType = "refund.failed"
+)
+
+// Event is a verified, decoded provider webhook. ID is unique per provider and is used for deduplication.
+// PaymentRef identifies the payment the event is about; RefundRef is set for refund events.
+type Event struct {
+	ID            string
+	Type          EventType
+	PaymentRef    string
+	RefundRef     string
+	Amount        money.Amount
+	Fee           money.Amount
+	FailureReason string
+	Raw           []byte
+}
+
+// ErrInvalidSignature is returned by ParseWebhook when the request is not authentic.
+var ErrInvalidSignature = errors.New("payment: invalid webhook signature")
+
+// DeclineError reports that the customer's payment method was declined.
+// It is a normal business outcome, unlike other errors which indicate provider trouble.
+type DeclineError struct {
+	Reason string
+}
+
+func (e *DeclineError) Error() string {
+	return fmt.Sprintf("payment declined: %s", e.Reason)
+}
@@ -0,0 +1,33 @@
+package payment
+
+import "fmt"
+
+// Registry holds the configured providers. New payments use the default provider;
+// existing payments and webhooks are routed by provider name.
+type Registry struct {
+	providers map[string]Provider
+	def       string
+}
+
+// NewRegistry registers providers and selects the one named def as default.
+func NewRegistry(def string, providers ...Provider) (*Registry, error) {
+	r := &Registry{providers: make(map[string]Provider), def: def}
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+	if _, ok := r.providers[def]; !ok {
+		return nil, fmt.Errorf("payment: unknown provider %q", def)
+	}
+	return r, nil
+}
+
+// Default returns the provider used for new payments.
+func (r *Registry) Default() Provider {
+	return r.providers[r.def]
+}
+
+// Get returns the provider with the given name.
+func (r *Registry) Get(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
@@ -0,0 +1,332 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/money"
+	"backend/internal/payment"
+)
+
+// Name is the provider name of the simulator.
+const Name = "simulator"
+
+// Payment methods understood by the simulator. An empty method behaves like MethodSucceed.
+const (
+	MethodSucceed      = "sim_succeed"       // authorizes immediately; capture succeeds
+	MethodDecline      = "sim_decline"       // declined by the "issuer"
+	MethodFail         = "sim_fail"          // provider error, as if the processor were down
+	MethodDelay        = "sim_delay"         // processing; succeeds after Config.Delay via webhook
+	MethodDelayDecline = "sim_delay_decline" // processing; fails after Config.Delay via webhook
+)
+
+// SignatureHeader carries "t=<unix>,v1=<hex hmac-sha256 of t.body>" on simulator webhooks.
+const SignatureHeader = "Simulator-Signature"
+
+// signatureTolerance bounds the age of an accepted webhook to limit replays.
+const signatureTolerance = 5 * time.Minute
+
+// Config controls the simulator's behaviour.
+type Config struct {
+	// Secret signs outbound webhooks and verifies inbound ones.
+	Secret []byte
+	// WebhookURL receives events for delayed payments; empty disables delivery (events are only logged).
+	WebhookURL string
+	// Delay is how long MethodDelay and MethodDelayDecline payments stay processing.
+	Delay time.Duration
+	// FeeBPS and FeeFixedMinor define the simulated processing fee: amount*FeeBPS/10000 + FeeFixedMinor.
+	FeeBPS        int64
+	FeeFixedMinor int64
+}
+
+// Simulator is an in-memory payment.Provider for tests and local development.
+type Simulator struct {
+	cfg    Config
+	client *http.Client
+
+	mu        sync.Mutex
+	intents   map[string]*intent
+	byIdemKey map[string]string
+	refunds   map[string]payment.Refund // keyed by idempotency key
+}
+
+type intent struct {
+	ref      string
+	orderID  string
+	amount   money.Amount
+	method   string
+	status   payment.Status
+	refunded int64
+}
+
+var _ payment.Provider = (*Simulator)(nil)
+
+// New returns a simulator with the given configuration.
+func New(cfg Config) *Simulator {
+	return &Simulator{
+		cfg:       cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		intents:   make(map[string]*intent),
+		byIdemKey: make(map[string]string),
+		refunds:   make(map[string]payment.Refund),
+	}
+}
+
+func (s *Simulator) Name() string { return Name }
+
+func (s *Simulator) CreateIntent(ctx context.Context, req payment.IntentRequest) (payment.Intent, error) {
+	if !req.Amount.IsPositive() {
+		return payment.Intent{}, errors.New("simulator: amount must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.IdempotencyKey != "" {
+		if ref, ok := s.byIdemKey[req.IdempotencyKey]; ok {
+			in := s.intents[ref]
+			return payment.Intent{Ref: in.ref, Status: in.status}, nil
+		}
+	}
+
+	in := &intent{ref: newID("sim_pi_"), orderID: req.OrderID, amount: req.Amount, method: req.PaymentMethod}
+	switch req.PaymentMethod {
+	case "", MethodSucceed:
+		in.status = payment.StatusRequiresCapture
+	case MethodDecline:
+		return payment.Intent{}, &payment.DeclineError{Reason: "card_declined"}
+	case MethodFail:
+		return payment.Intent{}, errors.New("simulator: processor unavailable")
+	case MethodDelay, MethodDelayDecline:
+		in.status = payment.StatusProcessing
+		succeed := req.PaymentMethod == MethodDelay
+		time.AfterFunc(s.cfg.Delay, func() { _ = s.Settle(in.ref, succeed) })
+	default:
+		return payment.Intent{}, &payment.DeclineError{Reason: "unsupported_payment_method"}
+	}
+
+	s.intents[in.ref] = in
+	if req.IdempotencyKey != "" {
+		s.byIdemKey[req.IdempotencyKey] = in.ref
+	}
+	return payment.Intent{Ref: in.ref, Status: in.status}, nil
+}
+
+func (s *Simulator) Capture(ctx context.Context, ref string) (payment.Capture, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	in, ok := s.intents[ref]
+	if !ok {
+		return payment.Capture{}, fmt.Errorf("simulator: unknown intent %s", ref)
+	}
+	switch in.status {
+	case payment.StatusRequiresCapture:
+		in.status = payment.StatusSucceeded
+	case payment.StatusSucceeded:
+		// Capturing twice is a no-op, like most real processors.
+	default:
+		return payment.Capture{}, fmt.Errorf("simulator: intent %s is %s", ref, in.status)
+	}
+	return payment.Capture{Status: in.status, Fee: s.fee(in.amount)}, nil
+}
+
+func (s *Simulator) Refund(ctx context.Context, req payment.RefundRequest) (payment.Refund, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.IdempotencyKey != "" {
+		if rf, ok := s.refunds[req.IdempotencyKey]; ok {
+			return rf, nil
+		}
+	}
+	in, ok := s.intents[req.PaymentRef]
+	if !ok {
+		return payment.Refund{}, fmt.Errorf("simulator: unknown intent %s", req.PaymentRef)
+	}
+	if in.status != payment.StatusSucceeded {
+		return payment.Refund{}, fmt.Errorf("simulator: intent %s is %s", req.PaymentRef, in.status)
+	}
+	if req.Amount.Currency != in.amount.Currency || !req.Amount.IsPositive() || in.refunded+req.Amount.Minor > in.amount.Minor {
+		return payment.Refund{}, fmt.Errorf("simulator: invalid refund amount for intent %s", req.PaymentRef)
+	}
+	in.refunded += req.Amount.Minor
+	rf := payment.Refund{Ref: newID("sim_re_"), Status: payment.StatusSucceeded}
+	if req.IdempotencyKey != "" {
+		s.refunds[req.IdempotencyKey] = rf
+	}
+	return rf, nil
+}
+
+// Settle completes a processing intent and emits the matching webhook. Delayed payments call it
+// automatically after Config.Delay; tests may call it directly to control timing.
+func (s *Simulator) Settle(ref string, succeed bool) error {
+	s.mu.Lock()
+	in, ok := s.intents[ref]
+	if !ok || in.status != payment.StatusProcessing {
+		s.mu.Unlock()
+		return fmt.Errorf("simulator: intent %s is not processing", ref)
+	}
+	ev := wireEvent{
+		ID:          newID("sim_evt_"),
+		PaymentRef:  in.ref,
+		AmountMinor: in.amount.Minor,
+		Currency:    in.amount.Currency,
+	}
+	if succeed {
+		in.status = payment.StatusSucceeded
+		ev.Type = payment.EventPaymentSucceeded
+		ev.FeeMinor = s.fee(in.amount).Minor
+	} else {
+		in.status = payment.StatusFailed
+		ev.Type = payment.EventPaymentFailed
+		ev.FailureReason = "card_declined"
+	}
+	s.mu.Unlock()
+
+	return s.emit(ev)
+}
+
+// ParseWebhook verifies the Simulator-Signature header and decodes the event.
+func (s *Simulator) ParseWebhook(r *http.Request) (payment.Event, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return payment.Event{}, err
+	}
+	if err := s.verify(r.Header.Get(SignatureHeader), body, time.Now()); err != nil {
+		return payment.Event{}, err
+	}
+
+	var ev wireEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return payment.Event{}, fmt.Errorf("simulator: decode webhook: %w", err)
+	}
+	if ev.ID == "" || ev.Type == "" {
+		return payment.Event{}, errors.New("simulator: webhook is missing id or type")
+	}
+	return payment.Event{
+		ID:            ev.ID,
+		Type:          ev.Type,
+		PaymentRef:    ev.PaymentRef,
+		RefundRef:     ev.RefundRef,
+		Amount:        money.FromMinor(ev.AmountMinor, ev.Currency),
+		Fee:           money.FromMinor(ev.FeeMinor, ev.Currency),
+		FailureReason: ev.FailureReason,
+		Raw:           body,
+	}, nil
+}
+
+// wireEvent is the JSON body of simulator webhooks.
+type wireEvent struct {
+	ID            string            `json:"id"`
+	Type          payment.EventType `json:"type"`
+	PaymentRef    string            `json:"payment_ref"`
+	RefundRef     string            `json:"refund_ref,omitempty"`
+	AmountMinor   int64             `json:"amount_minor"`
+	Currency      string            `json:"currency"`
+	FeeMinor      int64             `json:"fee_minor,omitempty"`
+	FailureReason string            `json:"failure_reason,omitempty"`
+}
+
+// emit posts a signed event to the configured webhook URL.
+func (s *Simulator) emit(ev wireEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	logger := slog.Default().With(
+		slog.String("component", "payment.simulator"),
+		slog.String("event_id", ev.ID),
+		slog.String("type", string(ev.Type)),
+	)
+	if s.cfg.WebhookURL == "" {
+		logger.Info("simulator event not delivered: no webhook URL configured")
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, s.sign(body, time.Now()))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		logger.Warn("simulator webhook delivery failed", slog.Any("err", err))
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		logger.Warn("simulator webhook rejected", slog.Int("status", resp.StatusCode))
+		return fmt.Errorf("simulator: webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *Simulator) sign(body []byte, now time.Time) string {
+	t := strconv.FormatInt(now.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(s.mac(t, body))
+}
+
+func (s *Simulator) verify(header string, body []byte, now time.Time) error {
+	var t, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			t = v
+		case "v1":
+			sig = v
+		}
+	}
+	ts, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || sig == "" {
+		return payment.ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return payment.ErrInvalidSignature
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, s.mac(t, body)) {
+		return payment.ErrInvalidSignature
+	}
+	return nil
+}
+
+func (s *Simulator) mac(t string, body []byte) []byte {
+	m := hmac.New(sha256.New, s.cfg.Secret)
+	m.Write([]byte(t))
+	m.Write([]byte("."))
+	m.Write(body)
+	return m.Sum(nil)
+}
+
+// fee computes the simulated processing fee, never exceeding the amount.
+func (s *Simulator) fee(a money.Amount) money.Amount {
+	f := a.Minor*s.cfg.FeeBPS/10000 + s.cfg.FeeFixedMinor
+	if f > a.Minor {
+		f = a.Minor
+	}
+	return money.FromMinor(f, a.Currency)
+}
+
+func newID(prefix string) string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return prefix + hex.EncodeToString(b)
+}
@@ -0,0 +1,401 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/money"
+	"backend/internal/payment"
+)
+
+var testSecret = []byte("test-secret")
+
+func newTestSimulator(webhookURL string) *Simulator {
+	return New(Config{Secret: testSecret, WebhookURL: webhookURL, Delay: time.Hour, FeeBPS: 290, FeeFixedMinor: 30})
+}
+
+func TestCreateIntent(t *testing.T) {
+	tests := []struct {
+		method  string
+		status  payment.Status
+		decline string // expected DeclineError reason
+		fail    bool   // expected provider error
+	}{
+		{method: "", status: payment.StatusRequiresCapture},
+		{method: MethodSucceed, status: payment.StatusRequiresCapture},
+		{method: MethodDelay, status: payment.StatusProcessing},
+		{method: MethodDelayDecline, status: payment.StatusProcessing},
+		{method: MethodDecline, decline: "card_declined"},
+		{method: "sim_unknown", decline: "unsupported_payment_method"},
+		{method: MethodFail, fail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			s := newTestSimulator("")
+			in, err := s.CreateIntent(context.Background(), payment.IntentRequest{
+				OrderID:       "order-1",
+				Amount:        money.FromMinor(1000, "USD"),
+				PaymentMethod: tt.method,
+			})
+			var de *payment.DeclineError
+			switch {
+			case tt.decline != "":
+				if !errors.As(err, &de) || de.Reason != tt.decline {
+					t.Fatalf("err = %v, want decline %q", err, tt.decline)
+				}
+			case tt.fail:
+				if err == nil || errors.As(err, &de) {
+					t.Fatalf("err = %v, want a provider error", err)
+				}
+			default:
+				if err != nil {
+					t.Fatal(err)
+				}
+				if in.Status != tt.status || !strings.HasPrefix(in.Ref, "sim_pi_") {
+					t.Fatalf("intent = %+v, want status %s", in, tt.status)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateIntentRejectsNonPositiveAmounts(t *testing.T) {
+	s := newTestSimulator("")
+	for _, minor := range []int64{0, -100} {
+		if _, err := s.CreateIntent(context.Background(), payment.IntentRequest{Amount: money.FromMinor(minor, "USD")}); err == nil {
+			t.Errorf("CreateIntent(%d) succeeded", minor)
+		}
+	}
+}
+
+func TestCreateIntentIsIdempotent(t *testing.T) {
+	s := newTestSimulator("")
+	req := payment.IntentRequest{Amount: money.FromMinor(1000, "USD"), IdempotencyKey: "pay-1"}
+	first, err := s.CreateIntent(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.CreateIntent(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Fatalf("retry returned %+v, want %+v", second, first)
+	}
+}
+
+func TestCapture(t *testing.T) {
+	s := newTestSimulator("")
+	ctx := context.Background()
+
+	in, err := s.CreateIntent(ctx, payment.IntentRequest{Amount: money.FromMinor(10000, "USD")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.Capture(ctx, in.Ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 10000 * 2.9% + 30
+	if c.Status != payment.StatusSucceeded || c.Fee != money.FromMinor(320, "USD") {
+		t.Fatalf("capture = %+v", c)
+	}
+	if again, err := s.Capture(ctx, in.Ref); err != nil || again != c {
+		t.Fatalf("second capture = %+v, %v; want %+v", again, err, c)
+	}
+
+	if _, err := s.Capture(ctx, "sim_pi_unknown"); err == nil {
+		t.Fatal("capture of an unknown intent succeeded")
+	}
+	delayed, err := s.CreateIntent(ctx, payment.IntentRequest{Amount: money.FromMinor(10000, "USD"), PaymentMethod: MethodDelay})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Capture(ctx, delayed.Ref); err == nil {
+		t.Fatal("capture of a processing intent succeeded")
+	}
+}
+
+func TestFeeNeverExceedsAmount(t *testing.T) {
+	s := newTestSimulator("")
+	tests := []struct {
+		amount, fee int64
+	}{
+		{10000, 320},
+		{100, 32},
+		{20, 20},
+		{1, 1},
+	}
+	for _, tt := range tests {
+		if got := s.fee(money.FromMinor(tt.amount, "USD")); got.Minor != tt.fee {
+			t.Errorf("fee(%d) = %d, want %d", tt.amount, got.Minor, tt.fee)
+		}
+	}
+}
+
+func TestRefund(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSimulator("")
+	in, err := s.CreateIntent(ctx, payment.IntentRequest{Amount: money.FromMinor(1000, "USD")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	uncaptured, err := s.CreateIntent(ctx, payment.IntentRequest{Amount: money.FromMinor(1000, "USD")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Capture(ctx, in.Ref); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		req  payment.RefundRequest
+		ok   bool
+	}{
+		{"unknown intent", payment.RefundRequest{PaymentRef: "sim_pi_unknown", Amount: money.FromMinor(100, "USD")}, false},
+		{"not captured", payment.RefundRequest{PaymentRef: uncaptured.Ref, Amount: money.FromMinor(100, "USD")}, false},
+		{"other currency", payment.RefundRequest{PaymentRef: in.Ref, Amount: money.FromMinor(100, "EUR")}, false},
+		{"zero", payment.RefundRequest{PaymentRef: in.Ref, Amount: money.FromMinor(0, "USD")}, false},
+		{"more than captured", payment.RefundRequest{PaymentRef: in.Ref, Amount: money.FromMinor(1001, "USD")}, false},
+		{"partial", payment.RefundRequest{PaymentRef: in.Ref, Amount: money.FromMinor(600, "USD"), IdempotencyKey: "rf-1"}, true},
+		{"partial retried", payment.RefundRequest{PaymentRef: in.Ref, Amount: money.FromMinor(600, "USD"), IdempotencyKey: "rf-1"}, true},
+		{"beyond the remainder", payment.RefundRequest{PaymentRef: in.Ref, Amount: money.FromMinor(401, "USD"), IdempotencyKey: "rf-2"}, false},
+		{"the remainder", payment.RefundRequest{PaymentRef: in.Ref, Amount: money.FromMinor(400, "USD"), IdempotencyKey: "rf-3"}, true},
+		{"after a full refund", payment.RefundRequest{PaymentRef: in.Ref, Amount: money.FromMinor(1, "USD"), IdempotencyKey: "rf-4"}, false},
+	}
+	refs := make(map[string]string)
+	for _, tt := range tests {
+		rf, err := s.Refund(ctx, tt.req)
+		if (err == nil) != tt.ok {
+			t.Fatalf("%s: err = %v, want ok=%v", tt.name, err, tt.ok)
+		}
+		if !tt.ok {
+			continue
+		}
+		if rf.Status != payment.StatusSucceeded || !strings.HasPrefix(rf.Ref, "sim_re_") {
+			t.Fatalf("%s: refund = %+v", tt.name, rf)
+		}
+		if prev, ok := refs[tt.req.IdempotencyKey]; ok && prev != rf.Ref {
+			t.Fatalf("%s: retry returned refund %s, want %s", tt.name, rf.Ref, prev)
+		}
+		refs[tt.req.IdempotencyKey] = rf.Ref
+	}
+}
+
+// webhookRecorder receives simulator webhooks and parses them with the simulator that sent them.
+type webhookRecorder struct {
+	events chan payment.Event
+	errs   chan error
+}
+
+func newWebhookServer(t *testing.T, s func() *Simulator) (*httptest.Server, *webhookRecorder) {
+	t.Helper()
+	rec := &webhookRecorder{events: make(chan payment.Event, 1), errs: make(chan error, 1)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ev, err := s().ParseWebhook(r)
+		if err != nil {
+			rec.errs <- err
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rec.events <- ev
+	}))
+	t.Cleanup(srv.Close)
+	return srv, rec
+}
+
+func TestSettle(t *testing.T) {
+	tests := []struct {
+		succeed bool
+		typ     payment.EventType
+		status  payment.Status
+	}{
+		{true, payment.EventPaymentSucceeded, payment.StatusSucceeded},
+		{false, payment.EventPaymentFailed, payment.StatusFailed},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.typ), func(t *testing.T) {
+			var s *Simulator
+			srv, rec := newWebhookServer(t, func() *Simulator { return s })
+			s = newTestSimulator(srv.URL)
+			ctx := context.Background()
+
+			in, err := s.CreateIntent(ctx, payment.IntentRequest{Amount: money.FromMinor(10000, "USD"), PaymentMethod: MethodDelay})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Settle(in.Ref, tt.succeed); err != nil {
+				t.Fatal(err)
+			}
+			var ev payment.Event
+			select {
+			case ev = <-rec.events:
+			case err := <-rec.errs:
+				t.Fatalf("webhook rejected: %v", err)
+			}
+			if ev.Type != tt.typ || ev.PaymentRef != in.Ref || ev.Amount != money.FromMinor(10000, "USD") {
+				t.Fatalf("event = %+v", ev)
+			}
+			if tt.succeed && ev.Fee != money.FromMinor(320, "USD") {
+				t.Fatalf("fee = %+v, want 320", ev.Fee)
+			}
+			if !tt.succeed && ev.FailureReason == "" {
+				t.Fatal("failed event has no reason")
+			}
+			if got := s.intents[in.Ref].status; got != tt.status {
+				t.Fatalf("intent status = %s, want %s", got, tt.status)
+			}
+
+			if err := s.Settle(in.Ref, tt.succeed); err == nil {
+				t.Fatal("settling twice succeeded")
+			}
+		})
+	}
+}
+
+func TestSettleRequiresProcessingIntent(t *testing.T) {
+	s := newTestSimulator("")
+	in, err := s.CreateIntent(context.Background(), payment.IntentRequest{Amount: money.FromMinor(1000, "USD")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Settle(in.Ref, true); err == nil {
+		t.Fatal("settling an intent awaiting capture succeeded")
+	}
+	if err := s.Settle("sim_pi_unknown", true); err == nil {
+		t.Fatal("settling an unknown intent succeeded")
+	}
+}
+
+func TestSettleReportsRejectedWebhooks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	s := newTestSimulator(srv.URL)
+	in, err := s.CreateIntent(context.Background(), payment.IntentRequest{Amount: money.FromMinor(1000, "USD"), PaymentMethod: MethodDelay})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Settle(in.Ref, true); err == nil {
+		t.Fatal("Settle ignored a rejected webhook")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	s := newTestSimulator("")
+	body := []byte(`{"id":"sim_evt_1","type":"payment.succeeded"}`)
+	now := time.Unix(1_800_000_000, 0)
+	valid := s.sign(body, now)
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	tests := []struct {
+		name   string
+		header string
+		body   []byte
+		now    time.Time
+		ok     bool
+	}{
+		{"valid", valid, body, now, true},
+		{"valid with spaces", strings.ReplaceAll(valid, ",", ", "), body, now, true},
+		{"at the tolerance", valid, body, now.Add(signatureTolerance), true},
+		{"too old", valid, body, now.Add(signatureTolerance + time.Second), false},
+		{"too far in the future", valid, body, now.Add(-signatureTolerance - time.Second), false},
+		{"tampered body", valid, []byte(`{"id":"sim_evt_2","type":"payment.succeeded"}`), now, false},
+		{"other secret", New(Config{Secret: []byte("other")}).sign(body, now), body, now, false},
+		{"timestamp changed", "t=" + strconv.FormatInt(now.Unix()+1, 10) + valid[strings.Index(valid, ","):], body, now, false},
+		{"missing signature", "t=" + ts, body, now, false},
+		{"missing timestamp", valid[strings.Index(valid, ",")+1:], body, now, false},
+		{"signature not hex", "t=" + ts + ",v1=zz", body, now, false},
+		{"empty", "", body, now, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.verify(tt.header, tt.body, tt.now)
+			if tt.ok && err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if !tt.ok && !errors.Is(err, payment.ErrInvalidSignature) {
+				t.Fatalf("err = %v, want %v", err, payment.ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestParseWebhook(t *testing.T) {
+	s := newTestSimulator("")
+	newRequest := func(body []byte, header string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/webhooks/simulator", strings.NewReader(string(body)))
+		r.Header.Set(SignatureHeader, header)
+		return r
+	}
+
+	body, err := json.Marshal(wireEvent{ID: "sim_evt_1", Type: payment.EventRefundSucceeded, PaymentRef: "sim_pi_1", RefundRef: "sim_re_1", AmountMinor: 500, Currency: "USD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, err := s.ParseWebhook(newRequest(body, s.sign(body, time.Now())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.ID != "sim_evt_1" || ev.Type != payment.EventRefundSucceeded || ev.RefundRef != "sim_re_1" || ev.Amount != money.FromMinor(500, "USD") || string(ev.Raw) != string(body) {
+		t.Fatalf("event = %+v", ev)
+	}
+
+	if _, err := s.ParseWebhook(newRequest(body, s.sign(body, time.Now().Add(-time.Hour)))); !errors.Is(err, payment.ErrInvalidSignature) {
+		t.Fatalf("stale webhook: err = %v, want %v", err, payment.ErrInvalidSignature)
+	}
+	if _, err := s.ParseWebhook(newRequest(body, "")); !errors.Is(err, payment.ErrInvalidSignature) {
+		t.Fatalf("unsigned webhook: err = %v, want %v", err, payment.ErrInvalidSignature)
+	}
+	for _, bad := range []string{`not json`, `{"type":"payment.succeeded"}`, `{"id":"sim_evt_1"}`} {
+		raw := []byte(bad)
+		if _, err := s.ParseWebhook(newRequest(raw, s.sign(raw, time.Now()))); err == nil || errors.Is(err, payment.ErrInvalidSignature) {
+			t.Errorf("body %s: err = %v, want a decode error", bad, err)
+		}
+	}
+}
+
+// TestPayCaptureRefund drives a payment through the simulator the way the order handlers do:
+// create the intent, capture it, then refund it in two parts.
+func TestPayCaptureRefund(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSimulator("")
+	amount := money.FromMinor(2500, "BZD")
+
+	in, err := s.CreateIntent(ctx, payment.IntentRequest{OrderID: "order-1", Amount: amount, PaymentMethod: MethodSucceed, IdempotencyKey: "payment-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.Status != payment.StatusRequiresCapture {
+		t.Fatalf("intent status = %s", in.Status)
+	}
+	c, err := s.Capture(ctx, in.Ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Status != payment.StatusSucceeded || c.Fee.Currency != "BZD" || c.Fee.Minor != 2500*290/10000+30 {
+		t.Fatalf("capture = %+v", c)
+	}
+
+	for i, minor := range []int64{1000, 1500} {
+		rf, err := s.Refund(ctx, payment.RefundRequest{PaymentRef: in.Ref, Amount: money.FromMinor(minor, "BZD"), IdempotencyKey: "refund-" + strconv.Itoa(i)})
+		if err != nil {
+			t.Fatalf("refund %d: %v", i, err)
+		}
+		if rf.Status != payment.StatusSucceeded {
+			t.Fatalf("refund %d status = %s", i, rf.Status)
+		}
+	}
+	if _, err := s.Refund(ctx, payment.RefundRequest{PaymentRef: in.Ref, Amount: money.FromMinor(1, "BZD"), IdempotencyKey: "refund-2"}); err == nil {
+		t.Fatal("refund beyond the captured amount succeeded")
+	}
+}
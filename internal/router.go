@@ -3,11 +3,13 @@ package internal
 import (
 	"context"
 	"database/sql"
+	"expvar"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
-	"os"
+	"sync"
 	"time"
 
 	httpx "backend/internal/httpx"
@@ -21,13 +23,31 @@ import (
 	"backend/internal/config"
 	"backend/internal/firebaseapp"
 	"backend/internal/health"
+	"backend/internal/idempotency"
+	"backend/internal/mail"
+	"backend/internal/model/apikey"
+	"backend/internal/model/business"
+	"backend/internal/model/currency"
+	"backend/internal/model/customer"
+	"backend/internal/model/invitation"
+	"backend/internal/model/ledger"
+	"backend/internal/model/notification"
 	"backend/internal/model/order"
+	"backend/internal/model/payout"
 	"backend/internal/model/user"
+	"backend/internal/model/webhook"
+	"backend/internal/payment"
+	"backend/internal/payment/simulator"
 )
 
 type httpServer struct{ http.Handler }
 
-func NewHTTPServer(cfg config.Config, db *sql.DB) (http.Handler, error) {
+// profileCacheSize bounds how many Firebase profiles the auth middleware keeps.
+const profileCacheSize = 10000
+
+// NewHTTPServer builds the API handler and starts its background workers. The returned stop
+// function cancels the workers and waits for them to return; call it once the server is shut down.
+func NewHTTPServer(cfg config.Config, db *sql.DB) (http.Handler, func(), error) {
 	r := chi.NewRouter()
 
 	// Core middlewares
@@ -44,31 +64,148 @@ func NewHTTPServer(cfg config.Config, db *sql.DB) (http.Handler, error) {
 	// Note: The actual spec will appear after running `swag init` and importing the generated docs package.
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
 
-	// Firebase client
+	// ID token verification: Firebase, the Firebase Auth emulator or a local key set.
 	var mw func(http.Handler) http.Handler
-	_, fbAuth, err := firebaseapp.New(context.Background(), cfg.FirebaseCredentialsFile)
+	verifier, accounts, err := newTokenVerifier(context.Background(), cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Providers that support it can reject revoked tokens and revoke a user's sessions.
+	var revocation *auth.RevocationCheck
+	var sessions auth.SessionRevoker
+	if rv, ok := verifier.(auth.RevocationVerifier); ok {
+		revocation = auth.NewRevocationCheck(rv, auth.RevocationMode(cfg.AuthRevocationCheck), cfg.AuthRevocationTTL)
+		sessions = revocation
+	} else if cfg.AuthRevocationCheck != string(auth.RevocationOff) {
+		slog.Warn("AUTH_REVOCATION_CHECK is ignored: the token verifier cannot check revocation", slog.String("auth_mode", cfg.AuthMode))
+	}
+	// Every private route authenticates first, then honors Idempotency-Key on mutating requests.
+	authMW := auth.NewFirebaseMiddleware(verifier, revocation)
+	// Routes that need the member's email even when the token lacks it look it up through a cache.
+	profileMW := auth.WithProfile(nil)
+	if lookup, ok := verifier.(auth.ProfileLookup); ok {
+		profileMW = auth.WithProfile(auth.NewProfileCache(lookup, profileCacheSize, cfg.AuthProfileCacheTTL))
+	}
+	idemMW := idempotency.Middleware(db, cfg.IdempotencyTTL)
+	mw = func(next http.Handler) http.Handler { return authMW(idemMW(next)) }
+	// Routes used by point-of-sale backends also accept business API keys (Bearer pk_...).
+	keyMW := auth.WithAPIKeys(auth.NewAPIKeyMiddleware(db), authMW)
+	serverMW := func(next http.Handler) http.Handler { return keyMW(idemMW(next)) }
+
+	// Runtime and cache metrics; they reveal traffic and usage patterns, so only operators see them.
+	r.With(authMW, auth.RequirePlatformAdmin).Get("/debug/vars", expvar.Handler().ServeHTTP)
+
+	// Transactional emails are queued in the notification outbox and delivered in the background.
+	mailer, err := mail.New(mail.Config{
+		Driver:       cfg.MailDriver,
+		From:         cfg.MailFrom,
+		FileDir:      cfg.MailFileDir,
+		SMTPAddr:     cfg.SMTPAddr,
+		SMTPUsername: cfg.SMTPUsername,
+		SMTPPassword: cfg.SMTPPassword,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Payment providers. The simulator collects no money, so it is only registered on explicit opt-in.
+	var providers []payment.Provider
+	if cfg.PaymentSimulatorEnabled {
+		providers = append(providers, simulator.New(simulator.Config{
+			Secret:        cfg.PaymentSimulatorSecret,
+			WebhookURL:    cfg.PaymentSimulatorWebhookURL,
+			Delay:         cfg.PaymentSimulatorDelay,
+			FeeBPS:        290,
+			FeeFixedMinor: 30,
+		}))
+	}
+	payments, err := payment.NewRegistry(cfg.PaymentProvider, providers...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	mw = auth.NewFirebaseMiddleware(fbAuth)
+
+	// Background workers run until stop is called. They are started last so a failed setup leaks none.
+	workers, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	run := func(worker func(ctx context.Context)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker(workers)
+		}()
+	}
+	run(func(ctx context.Context) { idempotency.RunJanitor(ctx, db, time.Hour) })
+	run(func(ctx context.Context) { webhook.RunDispatcher(ctx, db, 5*time.Second, cfg.WebhookAllowInsecure) })
+	run(func(ctx context.Context) { payout.RunScheduler(ctx, db, time.Minute, cfg.PayoutSettlementDelay) })
+	run(func(ctx context.Context) { order.RunExpirer(ctx, db, time.Minute, cfg.OrderTTL) })
+	run(func(ctx context.Context) { order.RunReconciler(ctx, db, time.Minute) })
+	run(func(ctx context.Context) { notification.RunSender(ctx, db, mailer, 5*time.Second) })
+	stop := func() {
+		cancel()
+		wg.Wait()
+	}
+
+	// Public payment links; customers paying an order are not Firebase users.
+	r.Mount("/pay", order.PaymentLinkRoutes(db, payments))
+
+	// Provider callbacks; authenticated by the provider's signature instead of Firebase.
+	r.Mount("/webhooks", order.ProviderWebhookRoutes(db, payments))
 
 	// API endpoints
 	r.Route("/api", func(api chi.Router) {
 		// User endpoints (public and private combined)
-		api.Mount("/user", user.Routes(db, fbAuth, mw))
+		api.Mount("/user", user.Routes(db, accounts, sessions, mw))
+
+		// Public reference data
+		api.Mount("/currencies", currency.Routes())
 
 		// Private API endpoints (with auth middleware)
-		api.With(mw).Mount("/orders", order.Routes(db))
+		api.With(serverMW).Mount("/orders", order.Routes(db, payments, cfg.PaymentLinkTTL, cfg.PublicURL))
+		api.With(serverMW).Mount("/refunds", order.RefundRoutes(db))
+		api.With(serverMW).Mount("/ledger", ledger.Routes(db))
+		api.With(serverMW).Mount("/payouts", payout.Routes(db))
+		api.With(serverMW).Mount("/customers", customer.Routes(db, order.CustomerOrders(db)))
+		api.With(mw).Mount("/businesses", business.Routes(db))
+		api.With(mw, profileMW).Mount("/invitations", invitation.Routes(db, cfg.InviteSigningKey, cfg.InviteTTL, cfg.PublicURL))
+		api.With(mw).Mount("/webhooks", webhook.Routes(db, cfg.WebhookAllowInsecure))
+		api.With(mw).Mount("/api-keys", apikey.Routes(db))
+		api.With(mw, auth.RequirePlatformAdmin).Mount("/provider-events", order.ReviewRoutes(db))
 	})
 
 	// Catch-all must be last so it doesn't shadow /api/* and /swagger/*
 	target, err := url.Parse(cfg.WebsiteURL)
 	if err != nil {
-		slog.Error("parsing website url failed", slog.Any("err", err))
-		os.Exit(1)
+		stop()
+		return nil, nil, fmt.Errorf("parsing website url: %w", err)
 	}
 
 	r.Handle("/*", httputil.NewSingleHostReverseProxy(target))
 
-	return &httpServer{r}, nil
+	return &httpServer{r}, stop, nil
+}
+
+// newTokenVerifier builds the ID token verifier and account creator selected by cfg.AuthMode.
+func newTokenVerifier(ctx context.Context, cfg config.Config) (auth.TokenVerifier, auth.AccountCreator, error) {
+	switch cfg.AuthMode {
+	case "emulator":
+		_, fbAuth, err := firebaseapp.NewEmulator(ctx, cfg.FirebaseProjectID)
+		if err != nil {
+			return nil, nil, err
+		}
+		slog.Warn("verifying ID tokens with the Firebase Auth emulator", slog.String("host", cfg.FirebaseEmulatorHost))
+		return auth.Firebase{Client: fbAuth}, auth.Firebase{Client: fbAuth}, nil
+	case "jwks":
+		v, err := auth.NewJWKSVerifier(ctx, cfg.AuthJWKS, cfg.AuthIssuer, cfg.AuthAudience)
+		if err != nil {
+			return nil, nil, err
+		}
+		slog.Warn("verifying ID tokens with a local key set; registration creates local accounts", slog.String("jwks", cfg.AuthJWKS))
+		return v, auth.LocalAccounts{}, nil
+	default:
+		_, fbAuth, err := firebaseapp.New(ctx, cfg.FirebaseCredentialsFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		return auth.Firebase{Client: fbAuth}, auth.Firebase{Client: fbAuth}, nil
+	}
 }